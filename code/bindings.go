@@ -4,126 +4,325 @@ import (
 	"strings"
 	"fmt"
     "strconv"
-)
-
-// @TODO Should we add 'scroll sensitivity'?
-// @TODO When a gamepad button is held down, the mouse or keyboard input should fire continuously.
-
-// Thumbstick value scaling
-const (
-    Constant = iota
-	Linear
-	Squared
-	Cubed
+    "math"
 )
 
 type Bindings struct {
     Bindings            map[*GamepadInput]*MouseOrKeyboardInput
-    ThumbstickScaling   int
+    StickBindings       []*StickBinding
+    RadialMenus         []*RadialMenu
+    AbsoluteCursors     []*AbsoluteCursor
+    FlickSticks         []*FlickStick
+    StickMice           []*StickMouse
+    Gestures            []*StickGesture
+    Sequences           []*ButtonSequence
     MouseSensitivity    float64
-    ThumbstickDeadZone  float64
-    TriggerThreshold    float64
+    MouseSpeed          float64 // Pixels per second at full deflection, before sensitivity
+    ScrollSpeed         float64 // Notches per second at full deflection
+    RepeatDelay         float64 // Seconds before a held key starts repeating
+    RepeatRate          float64 // Key repeats per second
+    OutputRate          float64 // Continuous outputs are sent this many times per second
+    CountsPer360        float64 // Mouse counts for a full turn in the game, for flick sticks
+    FlickTime           float64 // Seconds a flick is spread over
+    FlickThreshold      float64
+    TrackballFriction   float64
+    RingAcceleration    float64 // 0 turns ring acceleration off
+    RingMaxSpeed        float64
+    GestureWindow       float64 // Seconds a stick gesture must fit in
+    GestureTolerance    float64 // Degrees a gesture's directions may be off by
+    SequenceGap         float64 // Seconds allowed between the steps of a button sequence
+    DwellEnabled        bool
+    Dwell               DwellClicker // Copied into each Controller if enabled
+    StickyModifiers     bool
+    StickyDoublePress   float64 // Seconds between two presses that lock a sticky modifier
+    CopilotSlot         int // Slot of the gamepad merged into controller 0, -1 for none
+    CopilotPriority     int
+    Config              ProcessingConfig // Copied into each Controller
+    OuterRing           float64
+    AbsoluteArea        CursorArea
+    AbsoluteAnchorX     float64
+    AbsoluteAnchorY     float64
 }
 
 func NewBindings() Bindings {
     b := Bindings{}
 	b.Bindings = map[*GamepadInput]*MouseOrKeyboardInput{}
-    b.ThumbstickScaling  = Linear
     b.MouseSensitivity   = 1.0
-    b.ThumbstickDeadZone = DefaultThumbstickDeadZone
-    b.TriggerThreshold   = DefaultTriggerThreshold
+    b.MouseSpeed         = DefaultMouseSpeed
+    b.ScrollSpeed        = DefaultScrollSpeed
+    b.RepeatDelay        = DefaultRepeatDelay
+    b.RepeatRate         = DefaultRepeatRate
+    b.OutputRate         = DefaultOutputRate
+    b.CountsPer360       = DefaultCountsPer360
+    b.FlickTime          = DefaultFlickTime
+    b.FlickThreshold     = DefaultFlickThreshold
+    b.TrackballFriction  = DefaultTrackballFriction
+    b.RingAcceleration   = DefaultRingAcceleration
+    b.RingMaxSpeed       = DefaultRingMaxSpeed
+    b.GestureWindow      = DefaultGestureWindow
+    b.GestureTolerance   = DefaultGestureTolerance
+    b.SequenceGap        = DefaultSequenceGap
+    b.Dwell              = NewDwellClicker()
+    b.StickyDoublePress  = DefaultStickyDoublePress
+    b.CopilotSlot        = -1
+    b.CopilotPriority    = CopilotLargest
+    b.Config             = NewProcessingConfig()
+    b.OuterRing          = DefaultOuterRing
+    b.AbsoluteArea       = NewScreenArea()
+    b.AbsoluteAnchorX    = 0.5
+    b.AbsoluteAnchorY    = 0.5
     return b
 }
 
+// Returned by parseInput and parseConstant when they don't recognize the
+// left hand side at all, as opposed to recognizing it and failing.
+var errNotAnInput = fmt.Errorf("left hand side isn't a gamepad input.")
+var errNotAConstant = fmt.Errorf("left hand side is not a known constant.")
+
 func ParseBindings(contents string) (Bindings, error) {
+    bindings := NewBindings()
+    file, err := ParseYay(contents)
+    if err != nil {
+        return bindings, err
+    }
 
-    reportError := func(lineNumber int, errorString string) error {
-        return fmt.Errorf("Error on line %d: %s", lineNumber, errorString)
+    for _, statement := range(file.Statements) {
+        inputError := parseInput(&bindings, statement)
+        if inputError == nil {
+            continue
+        }
+        constantError := parseConstant(&bindings, statement)
+        if constantError == nil {
+            continue
+        }
+        // Report whichever of the two understood the left hand side.
+        if inputError != errNotAnInput {
+            return bindings, inputError
+        } else if constantError != errNotAConstant {
+            return bindings, constantError
+        }
+        return bindings, statement.Lhs[0].Errorf("left hand side is neither a gamepad input nor a known constant.")
     }
 
-    bindings := NewBindings()
-    contents = strings.Replace(contents, "\r\n", "\n", -1) // Remove Windows carriage return
-    lines := strings.Split(contents, "\n")
+    // Apply the outer ring.
+    for _,v := range(bindings.StickBindings) {
+        v.OuterRing = float32(bindings.OuterRing)
+    }
 
-    for i, line := range(lines) {
-		line = strings.SplitN(line, "#", 2)[0] // Split the line at the first occurence of '#' and get the left side
-		line = strings.TrimSpace(line)
-		if len(line) == 0 {
-			continue
-		}
-        line = strings.ToUpper(line)
-        line = strings.Replace(line, "_", "", -1) // Remove underscores
-		split := strings.Split(line, "=")
-		if len(split) != 2 {
-            return bindings, reportError(i+1, "expected exactly one equals sign.")
-		}
+    // Apply the flick stick settings.
+    for _,v := range(bindings.FlickSticks) {
+        v.CountsPer360 = bindings.CountsPer360
+        v.FlickTime = bindings.FlickTime
+        v.Threshold = float32(bindings.FlickThreshold)
+    }
 
-        lhs := strings.TrimSpace(split[0])
-        rhs := strings.TrimSpace(split[1])
-        if len(lhs) == 0 {
-            return bindings, reportError(i+1, "empty left hand side.")
-        }
-        if len(rhs) == 0 {
-            return bindings, reportError(i+1, "empty right hand side.")
-        }
+    // Apply the stick mouse settings.
+    for _,v := range(bindings.StickMice) {
+        v.Speed = bindings.MouseSpeed * bindings.MouseSensitivity
+        v.Friction = bindings.TrackballFriction
+        v.RingAcceleration = bindings.RingAcceleration
+        v.RingMaxSpeed = math.Max(bindings.RingMaxSpeed, 1)
+    }
 
-        inputError := parseInput(&bindings, lhs, rhs)
-        var constantError error = nil
-        if inputError != nil {
-            constantError = parseConstant(&bindings, lhs, rhs)
-        }
+    // Apply the gesture settings.
+    for _,v := range(bindings.Gestures) {
+        v.Window = bindings.GestureWindow
+        v.Tolerance = bindings.GestureTolerance
+    }
 
-        if inputError != nil && constantError != nil {
-            // @TODO: Make use of the error messages.
-            // We don't know if the user attempted to bind an input or assign a constant so we cannot give a better error message.
-            return bindings, reportError(i+1, "could not convert this line into a binding or constant assignment.")
+    // Apply the sequence gap to sequences without their own.
+    for _,v := range(bindings.Sequences) {
+        if v.Gap == 0 {
+            v.Gap = bindings.SequenceGap
         }
     }
 
-    // Apply mouse sensitivity.
-    for _,v := range(bindings.Bindings) {
-        if v.IsMouseMove {
-            v.X = LONG(float64(v.X) * bindings.MouseSensitivity)
-            v.Y = LONG(float64(v.Y) * bindings.MouseSensitivity)
-        }
+    // Apply the absolute cursor area and anchor.
+    for _,v := range(bindings.AbsoluteCursors) {
+        v.Area = bindings.AbsoluteArea
+        v.AnchorX = bindings.AbsoluteAnchorX
+        v.AnchorY = bindings.AbsoluteAnchorY
     }
 
 	return bindings, nil
 }
 
 // @TODO Support RHS keycodes like 0x50.
-func parseInput(bindings *Bindings, lhs, rhs string) (error) {
+func parseInput(bindings *Bindings, statement Statement) (error) {
+    lhs, rhs := statement.Lhs, statement.Rhs
+    for _, token := range(lhs) {
+        if token.Kind == PlusToken {
+            return parseRadialMenu(bindings, lhs, rhs)
+        }
+    }
+    if len(lhs) > 1 {
+        if _, found := StringToThumbstick[lhs[0].Word()]; found && len(lhs) == 2 {
+            return parseGesture(bindings, lhs[0], lhs[1], rhs)
+        }
+        return parseSequence(bindings, lhs, rhs)
+    }
+    name := lhs[0].Word()
+    if isLeft, found := StringToThumbstick[name]; found {
+        return parseStickBinding(bindings, isLeft, rhs)
+    }
+
     // Gamepad input
-    button, found := StringToGamepadButton[lhs]
+    button, found := StringToGamepadButton[name]
     var gpInput GamepadInput
     if found {
         gpInput = NewGamepadButtonInput(WORD(button))
-    } else if lhs == "LTRIGGER" || lhs == "LEFTTRIGGER" {
+    } else if name == "LTRIGGER" || name == "LEFTTRIGGER" {
         gpInput = NewGamepadTriggerInput(true)
-    } else if lhs == "RTRIGGER" || lhs == "RIGHTTRIGGER" {
+    } else if name == "RTRIGGER" || name == "RIGHTTRIGGER" {
         gpInput = NewGamepadTriggerInput(false)
-    } else if lhs == "LTHUMBX" || lhs ==  "LEFTTHUMBX" ||
-              lhs == "LEFTTHUMBSTICKX" || lhs == "LEFTSTICKX" ||
-              lhs == "LSTICKX" || lhs == "LTHUMBSTICKX" {
+    } else if name == "LTHUMBX" || name ==  "LEFTTHUMBX" ||
+              name == "LEFTTHUMBSTICKX" || name == "LEFTSTICKX" ||
+              name == "LSTICKX" || name == "LTHUMBSTICKX" {
         gpInput = NewGamepadThumbstickInput(true, true)
-    } else if lhs == "LTHUMBY" || lhs ==  "LEFTTHUMBY" ||
-              lhs == "LEFTTHUMBSTICKY" || lhs == "LEFTSTICKY" ||
-              lhs == "LSTICKY" || lhs == "LTHUMBSTICKY" {
+    } else if name == "LTHUMBY" || name ==  "LEFTTHUMBY" ||
+              name == "LEFTTHUMBSTICKY" || name == "LEFTSTICKY" ||
+              name == "LSTICKY" || name == "LTHUMBSTICKY" {
         gpInput = NewGamepadThumbstickInput(true, false)
-    } else if lhs == "RTHUMBX" || lhs ==  "RIGHTTHUMBX" ||
-              lhs == "RIGHTTHUMBSTICKX" || lhs == "RIGHTSTICKX" ||
-              lhs == "RSTICKX" || lhs == "RTHUMBSTICKX" {
+    } else if name == "RTHUMBX" || name ==  "RIGHTTHUMBX" ||
+              name == "RIGHTTHUMBSTICKX" || name == "RIGHTSTICKX" ||
+              name == "RSTICKX" || name == "RTHUMBSTICKX" {
         gpInput = NewGamepadThumbstickInput(false, true)
-    } else if lhs == "RTHUMBY" || lhs ==  "RIGHTTHUMBY" ||
-              lhs == "RIGHTTHUMBSTICKY" || lhs == "RIGHTSTICKY" ||
-              lhs == "RSTICKY" || lhs == "RTHUMBSTICKY" {
+    } else if name == "RTHUMBY" || name ==  "RIGHTTHUMBY" ||
+              name == "RIGHTTHUMBSTICKY" || name == "RIGHTSTICKY" ||
+              name == "RSTICKY" || name == "RTHUMBSTICKY" {
         gpInput = NewGamepadThumbstickInput(false, false)
     } else {
-        return fmt.Errorf("left hand side isn't a gamepad input.")
+        return errNotAnInput
     }
     // It is guaranteed that gpInput is set at this point
 
-    key, found := StringToKeyboardKey[rhs]
+    output, options, err := splitOptions(rhs)
+    if err != nil {
+        return err
+    }
+    err = parseBindingOptions(&gpInput, options)
+    if err != nil {
+        return err
+    }
+    mkInput, err := parseOutput(output)
+    if err != nil {
+        return err
+    }
+    if gpInput.Pulse > 0 && !mkInput.IsKeyboard && !mkInput.IsMouseButton {
+        return output[0].Errorf("only keys and mouse buttons can pulse.")
+    }
+    // It is guaranteed that mkInput is set at this point
+    // @TODO What do we do if the key/value is already assigned?
+    bindings.Bindings[&gpInput] = &mkInput
+    return nil
+}
+
+// Words that start an option at the end of a binding.
+var BindingOptions = []string{"CURVE", "TURBO", "PULSE", "HYSTERESIS", "RAPID", "GAP", "SWALLOW"}
+
+// An option at the end of a binding, eg "TURBO 10".
+type bindingOption struct {
+    Name    Token
+    Values  []Token
+}
+
+// Splits "SPACE TURBO 10" into the output "SPACE" and the options.
+func splitOptions(rhs []Token) ([]Token, []bindingOption, error) {
+    output := rhs
+    options := []bindingOption{}
+    for i, token := range(rhs) {
+        isOption := false
+        for _, name := range(BindingOptions) {
+            isOption = isOption || token.Word() == name
+        }
+        if isOption {
+            if len(options) == 0 {
+                output = rhs[:i]
+            }
+            options = append(options, bindingOption{Name: token})
+        } else if len(options) > 0 {
+            option := &options[len(options)-1]
+            option.Values = append(option.Values, token)
+        }
+    }
+    if len(output) == 0 {
+        return nil, nil, rhs[0].Errorf("expected an output before %s.", rhs[0].Text)
+    }
+    return output, options, nil
+}
+
+// Options are:
+//  CURVE, followed by a response curve that replaces the thumbstick's.
+//  TURBO, followed by how many times per second to press the output while held.
+//  PULSE, followed by a period in seconds. The output is pressed once per
+//  period and held for the fraction of it that the input is deflected.
+//  HYSTERESIS, followed by the press and release thresholds, see Hysteresis.
+//  RAPID, followed by a distance in raw trigger units, see RapidTrigger.
+func parseBindingOptions(gpInput *GamepadInput, options []bindingOption) (error) {
+    for _, option := range(options) {
+        switch option.Name.Word() {
+            case "CURVE":
+                curve, err := parseCurve(option.Name, option.Values)
+                if err != nil {
+                    return err
+                }
+                gpInput.Curve = &curve
+            case "TURBO":
+                numbers, err := parseNumbers(option.Name, option.Values, 1)
+                if err != nil {
+                    return err
+                }
+                if numbers[0] <= 0 {
+                    return option.Values[0].Errorf("turbo must be above zero presses per second.")
+                }
+                gpInput.Turbo = numbers[0]
+            case "PULSE":
+                numbers, err := parseNumbers(option.Name, option.Values, 1)
+                if err != nil {
+                    return err
+                }
+                if numbers[0] <= 0 {
+                    return option.Values[0].Errorf("the pulse period must be above zero seconds.")
+                }
+                gpInput.Pulse = numbers[0]
+            case "HYSTERESIS":
+                hysteresis, err := parseHysteresis(option.Name, option.Values)
+                if err != nil {
+                    return err
+                }
+                gpInput.Hysteresis = &hysteresis
+            case "RAPID":
+                if !gpInput.IsTrigger {
+                    return option.Name.Errorf("only triggers can use rapid trigger.")
+                }
+                numbers, err := parseNumbers(option.Name, option.Values, 1)
+                if err != nil {
+                    return err
+                }
+                if numbers[0] < 1 || numbers[0] > 255 {
+                    return option.Values[0].Errorf("the rapid trigger distance must be between 1 and 255.")
+                }
+                rapid := NewRapidTrigger(BYTE(numbers[0]))
+                gpInput.Rapid = &rapid
+            case "GAP", "SWALLOW":
+                return option.Name.Errorf("%s only applies to button sequences.", option.Name.Text)
+        }
+    }
+    return nil
+}
+
+// Converts the right hand side into a mouse or keyboard input. Everything but
+// a warp is a single word.
+func parseOutput(rhs []Token) (MouseOrKeyboardInput, error) {
+    if rhs[0].Word() == "WARP" {
+        return parseWarp(rhs[0], rhs[1:])
+    }
+    if len(rhs) > 1 {
+        return MouseOrKeyboardInput{}, rhs[1].Errorf("expected a single output.")
+    }
+    output := rhs[0].Word()
+
+    key, found := StringToKeyboardKey[output]
     var mkInput MouseOrKeyboardInput
     if found {
         // Keyboard input
@@ -148,14 +347,13 @@ func parseInput(bindings *Bindings, lhs, rhs string) (error) {
         } */
 
         // Mouse input
-        mouseButton, found := StringToMouseButton[rhs]
+        mouseButton, found := StringToMouseButton[output]
         if found {
             mkInput = NewMouseButtonInput(DWORD(mouseButton))
         } else {
-            switch rhs {
+            switch output {
                 case "SCROLLDOWN":
-                    // @TODO This should be negative, but that's outside the range of DWORD.
-                    mkInput = NewScrollInput(WHEEL_DELTA)
+                    mkInput = NewScrollInput(-WHEEL_DELTA)
                 case "SCROLLUP":
                     mkInput = NewScrollInput(WHEEL_DELTA)
                 case "MOUSEUP":
@@ -167,67 +365,873 @@ func parseInput(bindings *Bindings, lhs, rhs string) (error) {
                 case "MOUSERIGHT":
                     mkInput = NewMouseMoveInput(1, 0)
                 case "MOUSEX":
-                    panic("Unsupported") // @TODO
+                    mkInput = NewMouseAxisInput(1, 0)
                 case "MOUSEY":
-                    panic("Unsupported") // @TODO
+                    // The thumbstick's Y axis points up while the screen's points down.
+                    mkInput = NewMouseAxisInput(0, -1)
                 default:
-                    return fmt.Errorf("right hand side isn't a mouse or keyboard input.")
+                    return mkInput, rhs[0].Errorf("\"%s\" isn't a mouse or keyboard input.", rhs[0].Text)
             }
         }
     }
-    // It is guaranteed that mkInput is set at this point
-    // @TODO What do we do if the key/value is already assigned?
-    bindings.Bindings[&gpInput] = &mkInput
+    return mkInput, nil
+}
+
+// Warps look like "WARP CENTER, TOPLEFT LEFTCLICK, 0.25 0.75". Each point is either
+// a named point or two fractions of the virtual desktop, optionally followed by a
+// mouse button to click. The binding cycles through the points.
+func parseWarp(warp Token, rhs []Token) (MouseOrKeyboardInput, error) {
+    points := []WarpPoint{}
+    // Each point follows the WARP or a comma, which empty points are reported at.
+    start := warp
+    var fields []Token
+    for i := 0; i <= len(rhs); i++ {
+        if i < len(rhs) && rhs[i].Kind != CommaToken {
+            fields = append(fields, rhs[i])
+            continue
+        }
+        point, err := parseWarpPoint(start, fields)
+        if err != nil {
+            return MouseOrKeyboardInput{}, err
+        }
+        points = append(points, point)
+        if i < len(rhs) {
+            start = rhs[i]
+        }
+        fields = nil
+    }
+    return NewWarpInput(points), nil
+}
+
+func parseWarpPoint(start Token, fields []Token) (WarpPoint, error) {
+    var point WarpPoint
+    if len(fields) == 0 {
+        return point, start.Errorf("empty warp point.")
+    }
+    named, found := StringToWarpPoint[fields[0].Word()]
+    if found {
+        point = named
+        fields = fields[1:]
+    } else if len(fields) >= 2 {
+        x, err := parseNumber(fields[0])
+        if err != nil {
+            return point, fields[0].Errorf("\"%s\" isn't a named point or a number.", fields[0].Text)
+        }
+        y, err := parseNumber(fields[1])
+        if err != nil {
+            return point, err
+        }
+        point = WarpPoint{x, y, 0}
+        fields = fields[2:]
+    } else {
+        return point, fields[0].Errorf("\"%s\" isn't a named point.", fields[0].Text)
+    }
+    if len(fields) == 1 {
+        button, found := StringToMouseButton[fields[0].Word()]
+        if !found {
+            return point, fields[0].Errorf("\"%s\" isn't a mouse button.", fields[0].Text)
+        }
+        point.Click = button
+    } else if len(fields) > 1 {
+        return point, fields[1].Errorf("too many values in warp point.")
+    }
+    return point, nil
+}
+
+// Whole thumbstick bindings look like "LEFT_STICK = W A S D SHIFT", the keys being
+// up, left, down and right followed by an optional modifier held past the outer ring.
+// "LEFT_STICK = ABSOLUTE" maps the stick onto the screen instead and
+// "RIGHT_STICK = FLICK" makes it a flick stick. MOUSE and TRACKBALL move
+// the cursor with the whole stick.
+func parseStickBinding(bindings *Bindings, isLeft bool, rhs []Token) (error) {
+    mode := rhs[0].Word()
+    if len(rhs) == 1 && mode == "ABSOLUTE" {
+        cursor := NewAbsoluteCursor(isLeft)
+        bindings.AbsoluteCursors = append(bindings.AbsoluteCursors, &cursor)
+        return nil
+    } else if len(rhs) == 1 && (mode == "MOUSE" || mode == "TRACKBALL") {
+        mouse := NewStickMouse(isLeft, mode == "TRACKBALL")
+        bindings.StickMice = append(bindings.StickMice, mouse)
+        return nil
+    } else if len(rhs) == 1 && (mode == "FLICK" || mode == "FLICKSTICK") {
+        flick := NewFlickStick(isLeft)
+        bindings.FlickSticks = append(bindings.FlickSticks, &flick)
+        return nil
+    }
+
+    if len(rhs) > 5 {
+        return rhs[5].Errorf("expected four direction keys and an optional modifier.")
+    } else if len(rhs) < 4 {
+        return rhs[0].Errorf("expected four direction keys and an optional modifier.")
+    }
+    var keys [5]WORD
+    for i, token := range(rhs) {
+        key, found := StringToKeyboardKey[token.Word()]
+        if !found {
+            return token.Errorf("\"%s\" isn't a keyboard key.", token.Text)
+        }
+        keys[i] = WORD(key)
+    }
+    stick := NewStickBinding(isLeft, keys[0], keys[1], keys[2], keys[3])
+    if len(rhs) == 5 {
+        stick.Modifier = keys[4]
+        stick.HasModifier = true
+    }
+    bindings.StickBindings = append(bindings.StickBindings, &stick)
+    return nil
+}
+
+// Gestures look like "LEFT_STICK QCF = K", see StringToGesture.
+func parseGesture(bindings *Bindings, stick, gesture Token, rhs []Token) (error) {
+    isLeft, found := StringToThumbstick[stick.Word()]
+    if !found {
+        return stick.Errorf("a gesture must start with a thumbstick.")
+    }
+    kind, found := StringToGesture[gesture.Word()]
+    if !found {
+        return gesture.Errorf("unknown gesture.")
+    }
+    out, err := parseOutput(rhs)
+    if err != nil {
+        return err
+    }
+    g := NewStickGesture(isLeft, kind, &out)
+    bindings.Gestures = append(bindings.Gestures, &g)
+    return nil
+}
+
+// Sequences look like "UP UP DOWN DOWN LEFT RIGHT B A = F12", optionally
+// followed by GAP and the seconds allowed between steps, and by SWALLOW to
+// hide the steps from the other bindings.
+func parseSequence(bindings *Bindings, lhs []Token, rhs []Token) (error) {
+    steps := make([]WORD, len(lhs))
+    for i, token := range(lhs) {
+        button, found := StringToGamepadButton[token.Word()]
+        if !found {
+            return token.Errorf("\"%s\" isn't a gamepad button.", token.Text)
+        }
+        steps[i] = WORD(button)
+    }
+    output, options, err := splitOptions(rhs)
+    if err != nil {
+        return err
+    }
+    out, err := parseOutput(output)
+    if err != nil {
+        return err
+    }
+    seq := NewButtonSequence(steps, &out)
+    for _, option := range(options) {
+        switch option.Name.Word() {
+            case "GAP":
+                numbers, err := parseNumbers(option.Name, option.Values, 1)
+                if err != nil {
+                    return err
+                }
+                if numbers[0] <= 0 {
+                    return option.Values[0].Errorf("the gap must be above zero seconds.")
+                }
+                seq.Gap = numbers[0]
+            case "SWALLOW":
+                if len(option.Values) > 0 {
+                    return option.Values[0].Errorf("SWALLOW isn't followed by anything.")
+                }
+                seq.Swallow = true
+            default:
+                return option.Name.Errorf("%s doesn't apply to button sequences.", option.Name.Text)
+        }
+    }
+    bindings.Sequences = append(bindings.Sequences, &seq)
     return nil
 }
 
-func parseConstant(bindings *Bindings, lhs, rhs string) (error) {
-    if lhs == "DEADZONE" {
-        zone, err := strconv.ParseFloat(rhs, 64)
+// Radial menus look like "RBUMPER + RIGHT_STICK = 1 2 3 4", one output per slice
+// starting from straight up and going clockwise.
+func parseRadialMenu(bindings *Bindings, lhs []Token, rhs []Token) (error) {
+    if len(lhs) != 3 || lhs[1].Kind != PlusToken {
+        return lhs[0].Errorf("expected a button and a thumbstick on the left hand side.")
+    }
+    button, found := StringToGamepadButton[lhs[0].Word()]
+    if !found {
+        return lhs[0].Errorf("left hand side doesn't start with a gamepad button.")
+    }
+    isLeft, found := StringToThumbstick[lhs[2].Word()]
+    if !found {
+        return lhs[2].Errorf("left hand side doesn't end with a thumbstick.")
+    }
+
+    if len(rhs) < 2 {
+        return rhs[0].Errorf("a radial menu needs at least two slices.")
+    }
+    slices := []*MouseOrKeyboardInput{}
+    for i := range(rhs) {
+        out, err := parseOutput(rhs[i:i+1])
+        if err != nil {
+            return err
+        }
+        slices = append(slices, &out)
+    }
+    menu := NewRadialMenu(WORD(button), isLeft, slices)
+    bindings.RadialMenus = append(bindings.RadialMenus, &menu)
+    return nil
+}
+
+func parseConstant(bindings *Bindings, statement Statement) (error) {
+    if len(statement.Lhs) != 1 {
+        return errNotAConstant
+    }
+    lhs, equals, rhs := statement.Lhs[0].Word(), statement.Equals, statement.Rhs
+    if zones, setting, found := deadZoneConstant(bindings, lhs); found {
+        return parseDeadZone(zones, setting, equals, rhs)
+    } else if transform, setting, found := stickTransformConstant(bindings, lhs); found {
+        return parseStickTransform(transform, setting, equals, rhs)
+    } else if transform, setting, found := triggerTransformConstant(bindings, lhs); found {
+        return parseTriggerTransform(transform, setting, equals, rhs)
+    } else if settings, found := filterConstant(bindings, lhs); found {
+        filter, err := parseFilter(equals, rhs)
         if err == nil {
-            bindings.ThumbstickDeadZone = zone
-            return nil
-        } else {
-            return fmt.Errorf("right hand side isn't a number.")
+            *settings = filter
         }
+        return err
     } else if lhs == "THRESHOLD" {
-        thresh, err := strconv.ParseFloat(rhs, 64)
+        numbers, err := parseNumbers(equals, rhs, 1)
         if err == nil {
-            bindings.TriggerThreshold = thresh
-            return nil
-        } else {
-            return fmt.Errorf("right hand side isn't a number.")
+            bindings.Config.TriggerThreshold = numbers[0]
         }
+        return err
     } else if lhs == "MOUSESENSITIVITY" {
-        sens, err := strconv.ParseFloat(rhs, 64)
+        numbers, err := parseNumbers(equals, rhs, 1)
         if err == nil {
-            bindings.MouseSensitivity = sens
+            bindings.MouseSensitivity = numbers[0]
+        }
+        return err
+    } else if target, found := numberConstant(bindings, lhs); found {
+        numbers, err := parseNumbers(equals, rhs, 1)
+        if err != nil {
+            return err
+        }
+        if numbers[0] < 0 {
+            return rhs[0].Errorf("right hand side can't be negative.")
+        }
+        if lhs == "OUTPUTRATE" && numbers[0] == 0 {
+            return rhs[0].Errorf("the output rate must be above zero.")
+        } else if lhs == "DWELLTIME" && numbers[0] == 0 {
+            return rhs[0].Errorf("the dwell time must be above zero.")
+        }
+        *target = numbers[0]
+        return nil
+    } else if lhs == "DWELL" {
+        enabled, err := parseBool(equals, rhs)
+        if err == nil {
+            bindings.DwellEnabled = enabled
+        }
+        return err
+    } else if lhs == "STICKYMODIFIERS" || lhs == "STICKYKEYS" {
+        enabled, err := parseBool(equals, rhs)
+        if err == nil {
+            bindings.StickyModifiers = enabled
+        }
+        return err
+    } else if lhs == "COPILOT" {
+        if enabled, err := parseBool(equals, rhs); err == nil && !enabled {
+            bindings.CopilotSlot = -1
             return nil
-        } else {
-            return fmt.Errorf("right hand side isn't a number.")
         }
-    } else if lhs == "STICKSCALING" {
-        var scaling int
-        switch rhs {
-            case "CONSTANT":
-                scaling = Constant
-            case "LINEAR":
-                scaling = Linear
-            case "SQUARED":
-                scaling = Squared
-            case "CUBED":
-                scaling = Cubed
-            default:
-                scaling = -1
+        value, err := singleValue(equals, rhs)
+        if err != nil {
+            return err
         }
-        if scaling == -1 {
-            return fmt.Errorf("unknown scaling mode. Please use \"linear\", \"squared\" or \"cubed\".")
-        } else {
-            bindings.ThumbstickScaling = scaling
+        slot, err := strconv.Atoi(value.Word())
+        if err != nil || slot < 1 || slot > 3 {
+            return value.Errorf("expected OFF or the copilot's controller slot, 1 to 3.")
+        }
+        bindings.CopilotSlot = slot
+        return nil
+    } else if lhs == "COPILOTPRIORITY" {
+        value, err := singleValue(equals, rhs)
+        if err != nil {
+            return err
+        }
+        priority, found := StringToCopilotPriority[value.Word()]
+        if !found {
+            return value.Errorf("expected LARGEST, PILOT or COPILOT.")
+        }
+        bindings.CopilotPriority = priority
+        return nil
+    } else if lhs == "DWELLCYCLE" {
+        value, err := singleValue(equals, rhs)
+        if err != nil {
+            return err
+        }
+        button, found := StringToGamepadButton[value.Word()]
+        if !found {
+            return value.Errorf("\"%s\" isn't a gamepad button.", value.Text)
+        }
+        bindings.Dwell.CycleButton = WORD(button)
+        return nil
+    } else if lhs == "OUTERRING" {
+        numbers, err := parseNumbers(equals, rhs, 1)
+        if err == nil {
+            bindings.OuterRing = numbers[0]
+        }
+        return err
+    } else if lhs == "ABSOLUTEAREA" {
+        // Either SCREEN, WINDOW or "left top right bottom" in fractions of the screen.
+        if len(rhs) == 1 && rhs[0].Word() == "SCREEN" {
+            bindings.AbsoluteArea = NewScreenArea()
+            return nil
+        } else if len(rhs) == 1 && rhs[0].Word() == "WINDOW" {
+            bindings.AbsoluteArea = CursorArea{IsWindow: true}
             return nil
         }
+        numbers, err := parseNumbers(equals, rhs, 4)
+        if err != nil {
+            return err
+        }
+        bindings.AbsoluteArea = CursorArea{numbers[0], numbers[1], numbers[2], numbers[3], false}
+        return nil
+    } else if lhs == "ABSOLUTEANCHOR" {
+        numbers, err := parseNumbers(equals, rhs, 2)
+        if err != nil {
+            return err
+        }
+        bindings.AbsoluteAnchorX = numbers[0]
+        bindings.AbsoluteAnchorY = numbers[1]
+        return nil
+    } else if snaps, found := snapConstant(bindings, lhs); found {
+        snap, err := parseAngleSnap(equals, rhs)
+        if err != nil {
+            return err
+        }
+        for _, setting := range(snaps) {
+            *setting = snap
+        }
+        return nil
+    } else if lhs == "HYSTERESIS" {
+        hysteresis, err := parseHysteresis(equals, rhs)
+        if err == nil {
+            bindings.Config.Hysteresis = hysteresis
+        }
+        return err
+    } else if lhs == "SOCD" {
+        value, err := singleValue(equals, rhs)
+        if err != nil {
+            return err
+        }
+        mode, found := StringToSOCDMode[value.Word()]
+        if !found {
+            return value.Errorf("expected NONE, NEUTRAL, LAST_WINS, FIRST_WINS or UP_PRIORITY.")
+        }
+        bindings.Config.SOCD = mode
+        return nil
+    } else if bits, setting, found := debounceConstant(lhs); found {
+        numbers, err := parseNumbers(equals, rhs, 1)
+        if err != nil {
+            return err
+        } else if numbers[0] < 0 {
+            return rhs[0].Errorf("right hand side must not be negative.")
+        }
+        for _, bit := range(bits) {
+            if setting == "DEBOUNCE" {
+                bindings.Config.Debounce.Window[bit] = numbers[0]
+            } else {
+                bindings.Config.Debounce.MinPress[bit] = numbers[0]
+            }
+        }
+        return nil
+    } else if lhs == "STICKSCALING" {
+        // Kept for old bindings files. Sets the curve of both thumbsticks.
+        curve, err := parseCurve(equals, rhs)
+        if err != nil {
+            return err
+        }
+        bindings.Config.LeftStickCurve = curve
+        bindings.Config.RightStickCurve = curve
+        return nil
+    } else if lhs == "LEFTSTICKCURVE" || lhs == "LSTICKCURVE" {
+        curve, err := parseCurve(equals, rhs)
+        if err == nil {
+            bindings.Config.LeftStickCurve = curve
+        }
+        return err
+    } else if lhs == "RIGHTSTICKCURVE" || lhs == "RSTICKCURVE" {
+        curve, err := parseCurve(equals, rhs)
+        if err == nil {
+            bindings.Config.RightStickCurve = curve
+        }
+        return err
     } else {
-        return fmt.Errorf("left hand side is not a known constant.")
+        return errNotAConstant
     }
     panic("Internal error: unexpected code path")
 }
+
+// The value parsers below take the values and the token they follow, such as
+// the equals sign or an option, which errors about missing values point at.
+
+// Returns the only value.
+func singleValue(after Token, values []Token) (Token, error) {
+    if len(values) == 0 {
+        return after, after.Errorf("expected a value after %s.", after.Text)
+    } else if len(values) > 1 {
+        return values[1], values[1].Errorf("expected a single value.")
+    }
+    return values[0], nil
+}
+
+func parseNumber(token Token) (float64, error) {
+    number, err := strconv.ParseFloat(token.Word(), 64)
+    if err != nil {
+        return 0, token.Errorf("\"%s\" isn't a number.", token.Text)
+    }
+    return number, nil
+}
+
+// Parses exactly count numbers.
+func parseNumbers(after Token, values []Token, count int) ([]float64, error) {
+    expected := fmt.Sprintf("expected %d numbers after %s.", count, after.Text)
+    if count == 1 {
+        expected = fmt.Sprintf("expected a number after %s.", after.Text)
+    }
+    if len(values) > count {
+        return nil, values[count].Errorf("%s", expected)
+    } else if len(values) < count && len(values) > 0 {
+        return nil, values[len(values)-1].Errorf("%s", expected)
+    } else if len(values) < count {
+        return nil, after.Errorf("%s", expected)
+    }
+    numbers := make([]float64, count)
+    for i, value := range(values) {
+        number, err := parseNumber(value)
+        if err != nil {
+            return nil, err
+        }
+        numbers[i] = number
+    }
+    return numbers, nil
+}
+
+// Angle snapping looks like "RIGHT_STICK_SNAP = 15" or "STICK_SNAP = 15 5",
+// the optional second number being the hysteresis. Returns the settings affected.
+func snapConstant(bindings *Bindings, lhs string) ([]*AngleSnap, bool) {
+    switch lhs {
+        case "STICKSNAP":
+            return []*AngleSnap{&bindings.Config.LeftStickSnap, &bindings.Config.RightStickSnap}, true
+        case "LEFTSTICKSNAP", "LSTICKSNAP":
+            return []*AngleSnap{&bindings.Config.LeftStickSnap}, true
+        case "RIGHTSTICKSNAP", "RSTICKSNAP":
+            return []*AngleSnap{&bindings.Config.RightStickSnap}, true
+    }
+    return nil, false
+}
+
+func parseAngleSnap(after Token, values []Token) (AngleSnap, error) {
+    snap := NewAngleSnap()
+    if len(values) > 2 {
+        return snap, values[2].Errorf("expected a window and an optional hysteresis in degrees.")
+    }
+    numbers, err := parseNumbers(after, values, len(values))
+    if err != nil {
+        return snap, err
+    }
+    snap.Window = numbers[0]
+    if len(numbers) == 2 {
+        snap.Hysteresis = numbers[1]
+    }
+    if snap.Window < 0 || snap.Window + snap.Hysteresis > 45 || snap.Hysteresis < 0 {
+        at := values[len(values)-1]
+        if snap.Window < 0 {
+            at = values[0]
+        }
+        return snap, at.Errorf("the window and hysteresis must not be negative and must add up to 45 degrees at most.")
+    }
+    return snap, nil
+}
+
+// Debouncing looks like "DEBOUNCE = 0.02" for every button or
+// "A_MIN_PRESS = 0.03" for one. Returns the button bits affected and the
+// setting without the button.
+func debounceConstant(lhs string) ([]int, string, bool) {
+    for _, setting := range([]string{"DEBOUNCE", "MINPRESS"}) {
+        if !strings.HasSuffix(lhs, setting) {
+            continue
+        }
+        name := strings.TrimSuffix(lhs, setting)
+        if name == "" {
+            bits := make([]int, ButtonCount)
+            for i := range(bits) {
+                bits[i] = i
+            }
+            return bits, setting, true
+        }
+        button, found := StringToGamepadButton[name]
+        if !found {
+            return nil, "", false
+        }
+        for bit := 0; bit < ButtonCount; bit++ {
+            if button == 1 << uint(bit) {
+                return []int{bit}, setting, true
+            }
+        }
+    }
+    return nil, "", false
+}
+
+// Hysteresis looks like "0.5 0.3", the press threshold followed by the release one.
+func parseHysteresis(after Token, values []Token) (Hysteresis, error) {
+    numbers, err := parseNumbers(after, values, 2)
+    if err != nil {
+        return Hysteresis{}, err
+    }
+    press, release := numbers[0], numbers[1]
+    if press < 0 || press >= 1 {
+        return Hysteresis{}, values[0].Errorf("expected a press threshold below 1 and a release threshold between 0 and it.")
+    } else if release < 0 || release > press {
+        return Hysteresis{}, values[1].Errorf("expected a press threshold below 1 and a release threshold between 0 and it.")
+    }
+    return Hysteresis{press, release}, nil
+}
+
+// Curves are CONSTANT, LINEAR, SQUARED, CUBED, "POWER 2.5", "S_CURVE 2" or
+// "POINTS 0.5 0.2 0.8 0.6", the points being pairs of input and output values.
+func parseCurve(after Token, values []Token) (ResponseCurve, error) {
+    if len(values) == 0 {
+        return ResponseCurve{}, after.Errorf("expected a response curve.")
+    }
+    kind, parameters := values[0], values[1:]
+    switch kind.Word() {
+        case "CONSTANT", "LINEAR", "SQUARED", "CUBED":
+            if len(parameters) > 0 {
+                return ResponseCurve{}, parameters[0].Errorf("%s isn't followed by anything.", kind.Text)
+            }
+    }
+    switch kind.Word() {
+        case "CONSTANT":
+            return ResponseCurve{Kind: Constant}, nil
+        case "LINEAR":
+            return NewPowerCurve(1), nil
+        case "SQUARED":
+            return NewPowerCurve(2), nil
+        case "CUBED":
+            return NewPowerCurve(3), nil
+        case "POWER", "SCURVE":
+            numbers, err := parseNumbers(kind, parameters, 1)
+            if err != nil {
+                return ResponseCurve{}, err
+            }
+            if numbers[0] <= 0 {
+                return ResponseCurve{}, parameters[0].Errorf("the exponent must be above zero.")
+            }
+            if kind.Word() == "POWER" {
+                return NewPowerCurve(numbers[0]), nil
+            }
+            return NewSCurve(numbers[0]), nil
+        case "POINTS":
+            if len(parameters) == 0 {
+                return ResponseCurve{}, kind.Errorf("expected pairs of input and output values.")
+            } else if len(parameters) % 2 != 0 {
+                return ResponseCurve{}, parameters[len(parameters)-1].Errorf("expected pairs of input and output values.")
+            }
+            numbers, err := parseNumbers(kind, parameters, len(parameters))
+            if err != nil {
+                return ResponseCurve{}, err
+            }
+            points := []CurvePoint{}
+            for i, number := range(numbers) {
+                if number < 0 || number > 1 {
+                    return ResponseCurve{}, parameters[i].Errorf("curve points must be between 0 and 1.")
+                }
+                if i % 2 == 1 {
+                    points = append(points, CurvePoint{numbers[i-1], number})
+                }
+            }
+            return NewPiecewiseCurve(points), nil
+    }
+    return ResponseCurve{}, kind.Errorf("unknown response curve. Please use \"linear\", \"squared\", \"cubed\", \"power\", \"s_curve\" or \"points\".")
+}
+
+// Dead zone settings may start with LEFT or RIGHT to only affect one thumbstick,
+// eg "LEFT_OUTER_DEAD_ZONE = 0.05". Returns the dead zones affected and the
+// setting without the prefix.
+func deadZoneConstant(bindings *Bindings, lhs string) ([]*DeadZone, string, bool) {
+    zones := []*DeadZone{&bindings.Config.LeftDeadZone, &bindings.Config.RightDeadZone}
+    setting := lhs
+    for _, prefix := range([]string{"LEFTSTICK", "LSTICK", "LEFT"}) {
+        if strings.HasPrefix(lhs, prefix) {
+            zones = zones[:1]
+            setting = strings.TrimPrefix(lhs, prefix)
+            break
+        }
+    }
+    for _, prefix := range([]string{"RIGHTSTICK", "RSTICK", "RIGHT"}) {
+        if strings.HasPrefix(lhs, prefix) {
+            zones = zones[1:]
+            setting = strings.TrimPrefix(lhs, prefix)
+            break
+        }
+    }
+    switch setting {
+        case "DEADZONE", "DEADZONESHAPE", "OUTERDEADZONE", "ANTIDEADZONE":
+            return zones, setting, true
+    }
+    return nil, "", false
+}
+
+func parseDeadZone(zones []*DeadZone, setting string, equals Token, rhs []Token) (error) {
+    if setting == "DEADZONESHAPE" {
+        value, err := singleValue(equals, rhs)
+        if err != nil {
+            return err
+        }
+        shape, found := StringToDeadZoneShape[value.Word()]
+        if !found {
+            return value.Errorf("unknown dead zone shape. Please use \"axial\", \"radial\", \"scaled_radial\", \"hybrid\" or \"bow_tie\".")
+        }
+        for _, zone := range(zones) {
+            zone.Shape = shape
+        }
+        return nil
+    }
+
+    numbers, err := parseNumbers(equals, rhs, 1)
+    if err != nil {
+        return err
+    }
+    value := numbers[0]
+    if value < 0 || value >= 1 {
+        return rhs[0].Errorf("dead zones must be at least 0 and below 1.")
+    }
+    for _, zone := range(zones) {
+        switch setting {
+            case "DEADZONE":
+                zone.Inner = value
+            case "OUTERDEADZONE":
+                zone.Outer = value
+            case "ANTIDEADZONE":
+                zone.Anti = value
+        }
+    }
+    return nil
+}
+
+// Stick transforms look like "LEFT_STICK_ROTATE = 45". Returns the transform
+// affected and the setting without the prefix.
+func stickTransformConstant(bindings *Bindings, lhs string) (*StickTransform, string, bool) {
+    var transform *StickTransform
+    setting := lhs
+    for _, prefix := range([]string{"LEFTSTICK", "LSTICK"}) {
+        if strings.HasPrefix(lhs, prefix) {
+            transform = &bindings.Config.LeftStickTransform
+            setting = strings.TrimPrefix(lhs, prefix)
+        }
+    }
+    for _, prefix := range([]string{"RIGHTSTICK", "RSTICK"}) {
+        if strings.HasPrefix(lhs, prefix) {
+            transform = &bindings.Config.RightStickTransform
+            setting = strings.TrimPrefix(lhs, prefix)
+        }
+    }
+    switch setting {
+        case "ROTATE", "SWAP", "INVERTX", "INVERTY", "SCALE", "SCALEX", "SCALEY":
+            return transform, setting, transform != nil
+    }
+    return nil, "", false
+}
+
+func parseStickTransform(transform *StickTransform, setting string, equals Token, rhs []Token) (error) {
+    switch setting {
+        case "SWAP", "INVERTX", "INVERTY":
+            value, err := parseBool(equals, rhs)
+            if err != nil {
+                return err
+            }
+            if setting == "SWAP" {
+                transform.Swap = value
+            } else if setting == "INVERTX" {
+                transform.InvertX = value
+            } else {
+                transform.InvertY = value
+            }
+            return nil
+    }
+
+    numbers, err := parseNumbers(equals, rhs, 1)
+    if err != nil {
+        return err
+    }
+    value := numbers[0]
+    switch setting {
+        case "ROTATE":
+            transform.Rotation = value
+        case "SCALE":
+            transform.ScaleX = value
+            transform.ScaleY = value
+        case "SCALEX":
+            transform.ScaleX = value
+        case "SCALEY":
+            transform.ScaleY = value
+    }
+    return nil
+}
+
+// Trigger transforms look like "LEFT_TRIGGER_RANGE = 0.1 0.9". Returns the
+// transform affected and the setting without the prefix.
+func triggerTransformConstant(bindings *Bindings, lhs string) (*TriggerTransform, string, bool) {
+    var transform *TriggerTransform
+    setting := lhs
+    for _, prefix := range([]string{"LEFTTRIGGER", "LTRIGGER"}) {
+        if strings.HasPrefix(lhs, prefix) {
+            transform = &bindings.Config.LeftTriggerTransform
+            setting = strings.TrimPrefix(lhs, prefix)
+        }
+    }
+    for _, prefix := range([]string{"RIGHTTRIGGER", "RTRIGGER"}) {
+        if strings.HasPrefix(lhs, prefix) {
+            transform = &bindings.Config.RightTriggerTransform
+            setting = strings.TrimPrefix(lhs, prefix)
+        }
+    }
+    switch setting {
+        case "INVERT", "RANGE":
+            return transform, setting, transform != nil
+    }
+    return nil, "", false
+}
+
+func parseTriggerTransform(transform *TriggerTransform, setting string, equals Token, rhs []Token) (error) {
+    if setting == "INVERT" {
+        value, err := parseBool(equals, rhs)
+        if err == nil {
+            transform.Invert = value
+        }
+        return err
+    }
+    numbers, err := parseNumbers(equals, rhs, 2)
+    if err != nil {
+        return err
+    }
+    if numbers[0] < 0 || numbers[0] >= numbers[1] {
+        return rhs[0].Errorf("the range must be two increasing numbers between 0 and 1.")
+    } else if numbers[1] > 1 {
+        return rhs[1].Errorf("the range must be two increasing numbers between 0 and 1.")
+    }
+    transform.Min = numbers[0]
+    transform.Max = numbers[1]
+    return nil
+}
+
+func parseBool(after Token, values []Token) (bool, error) {
+    value, err := singleValue(after, values)
+    if err != nil {
+        return false, err
+    }
+    switch value.Word() {
+        case "TRUE", "YES", "ON", "1":
+            return true, nil
+        case "FALSE", "NO", "OFF", "0":
+            return false, nil
+    }
+    return false, value.Errorf("\"%s\" isn't true or false.", value.Text)
+}
+
+// Filters are set per stick or trigger, eg "LEFT_STICK_FILTER = MEDIAN 5".
+func filterConstant(bindings *Bindings, lhs string) (*FilterSettings, bool) {
+    switch lhs {
+        case "LEFTSTICKFILTER", "LSTICKFILTER":
+            return &bindings.Config.LeftStickFilter, true
+        case "RIGHTSTICKFILTER", "RSTICKFILTER":
+            return &bindings.Config.RightStickFilter, true
+        case "LEFTTRIGGERFILTER", "LTRIGGERFILTER":
+            return &bindings.Config.LeftTriggerFilter, true
+        case "RIGHTTRIGGERFILTER", "RTRIGGERFILTER":
+            return &bindings.Config.RightTriggerFilter, true
+    }
+    return nil, false
+}
+
+// Filters are NONE, "AVERAGE 0.3", "ONE_EURO 1.0 0.007 1.0" or "MEDIAN 5".
+// The one euro numbers are the minimum cutoff, beta and the derivative cutoff.
+func parseFilter(after Token, values []Token) (FilterSettings, error) {
+    if len(values) == 0 {
+        return FilterSettings{}, after.Errorf("expected a filter.")
+    }
+    kind, parameters := values[0], values[1:]
+    switch kind.Word() {
+        case "NONE":
+            if len(parameters) > 0 {
+                return FilterSettings{}, parameters[0].Errorf("%s isn't followed by anything.", kind.Text)
+            }
+            return FilterSettings{Kind: NoFilter}, nil
+        case "AVERAGE", "EMA":
+            numbers, err := parseNumbers(kind, parameters, 1)
+            if err != nil {
+                return FilterSettings{}, err
+            }
+            if numbers[0] <= 0 || numbers[0] > 1 {
+                return FilterSettings{}, parameters[0].Errorf("the weight must be above 0 and at most 1.")
+            }
+            return FilterSettings{Kind: Average, Alpha: numbers[0]}, nil
+        case "ONEEURO":
+            numbers, err := parseNumbers(kind, parameters, 3)
+            if err != nil {
+                return FilterSettings{}, err
+            }
+            for i, number := range(numbers) {
+                if number < 0 || number == 0 && i != 1 {
+                    return FilterSettings{}, parameters[i].Errorf("the cutoffs must be above 0 and beta can't be negative.")
+                }
+            }
+            return FilterSettings{Kind: OneEuro, MinCutoff: numbers[0], Beta: numbers[1], DerivativeCutoff: numbers[2]}, nil
+        case "MEDIAN":
+            numbers, err := parseNumbers(kind, parameters, 1)
+            if err != nil {
+                return FilterSettings{}, err
+            }
+            if numbers[0] < 1 || numbers[0] != math.Trunc(numbers[0]) {
+                return FilterSettings{}, parameters[0].Errorf("the sample count must be a whole number above 0.")
+            }
+            return FilterSettings{Kind: Median, Size: int(numbers[0])}, nil
+    }
+    return FilterSettings{}, kind.Errorf("unknown filter. Please use \"none\", \"average\", \"one_euro\" or \"median\".")
+}
+
+// Settings that are a single number.
+func numberConstant(bindings *Bindings, lhs string) (*float64, bool) {
+    switch lhs {
+        case "MOUSESPEED":
+            return &bindings.MouseSpeed, true
+        case "SCROLLSPEED":
+            return &bindings.ScrollSpeed, true
+        case "REPEATDELAY":
+            return &bindings.RepeatDelay, true
+        case "REPEATRATE":
+            return &bindings.RepeatRate, true
+        case "OUTPUTRATE":
+            return &bindings.OutputRate, true
+        case "COUNTSPER360":
+            return &bindings.CountsPer360, true
+        case "FLICKTIME":
+            return &bindings.FlickTime, true
+        case "FLICKTHRESHOLD":
+            return &bindings.FlickThreshold, true
+        case "TRACKBALLFRICTION", "FRICTION":
+            return &bindings.TrackballFriction, true
+        case "RINGACCELERATION":
+            return &bindings.RingAcceleration, true
+        case "RINGMAXSPEED":
+            return &bindings.RingMaxSpeed, true
+        case "GESTUREWINDOW":
+            return &bindings.GestureWindow, true
+        case "GESTURETOLERANCE":
+            return &bindings.GestureTolerance, true
+        case "SEQUENCEGAP":
+            return &bindings.SequenceGap, true
+        case "STICKYDOUBLEPRESS":
+            return &bindings.StickyDoublePress, true
+        case "DWELLTIME":
+            return &bindings.Dwell.Time, true
+        case "DWELLRADIUS":
+            return &bindings.Dwell.Radius, true
+    }
+    return nil, false
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+// Interpreters report errors at the token they are about.
+func TestParseBindingsErrorPosition(t *testing.T) {
+	tests := []struct {
+		contents  string
+		want      Position
+	}{
+		{"RTRIGGER = X RAPID 300", Position{1, 20}},
+		{"A = SPACE TURBO -1", Position{1, 17}},
+		{"A = SPACE TURBO", Position{1, 11}},
+		{"A = SPACE TURBO 1 2", Position{1, 19}},
+		{"LTRIGGER = X CURVE POINTS 0.5 1.5", Position{1, 31}},
+		{"LTRIGGER = X CURVE POINTS 0.5", Position{1, 27}},
+		{"A = SPACE RAPID 10", Position{1, 11}},
+		{"A = TURBO 10", Position{1, 5}},
+		{"RBUMPER + FOO = A B", Position{1, 11}},
+		{"RBUMPER + LSTICK = A", Position{1, 20}},
+		{"A = SPACE Q", Position{1, 11}},
+		{"A = NOTAKEY", Position{1, 5}},
+		{`A = "SPACE"`, Position{1, 5}},
+		{`A = "space key"`, Position{1, 5}},
+		{"# Line 1\nFOO = 1", Position{2, 1}},
+		{"HYSTERESIS = 0.5 0.6", Position{1, 18}},
+		{"LEFT_DEAD_ZONE = 1.5", Position{1, 18}},
+		{"LEFT_DEAD_ZONE = 0.1 0.2", Position{1, 22}},
+		{"THRESHOLD = half", Position{1, 13}},
+		{"LEFT_STICK_CURVE = POWER", Position{1, 20}},
+		{"LEFT_STICK_CURVE = LINEAR 2", Position{1, 27}},
+		{"LEFT_STICK_FILTER = ONE_EURO 1 0.1 0", Position{1, 36}},
+		{"DWELL = maybe", Position{1, 9}},
+		{"SOCD = NEUTRAL LAST_WINS", Position{1, 16}},
+		{"ABSOLUTE_AREA = 0 0 1", Position{1, 21}},
+		{"STICK_SNAP = 10 5 1", Position{1, 19}},
+	}
+	for _, test := range(tests) {
+		_, err := ParseBindings(test.contents)
+		got, ok := err.(*YayError)
+		if !ok || got.Pos != test.want {
+			t.Errorf("ParseBindings(%q) error = %v, want one at line %d, column %d", test.contents, err, test.want.Line, test.want.Column)
+		}
+	}
+}
+
+func TestParseBindings(t *testing.T) {
+	bindings, err := ParseBindings("a = Space TURBO 10\nleft_trigger = x RAPID 20\nLBUMPER + RSTICK = Q E\nHYSTERESIS = 0.6 0.4")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(bindings.Bindings) != 2 || len(bindings.RadialMenus) != 1 {
+		t.Errorf("got %d bindings and %d radial menus, want 2 and 1", len(bindings.Bindings), len(bindings.RadialMenus))
+	}
+	if bindings.Config.Hysteresis != (Hysteresis{0.6, 0.4}) {
+		t.Errorf("Hysteresis = %v, want {0.6 0.4}", bindings.Config.Hysteresis)
+	}
+}
+
+// The bindings file shipped with the program is kept valid.
+func TestParseBindingsFile(t *testing.T) {
+	contents, err := ioutil.ReadFile("bindings.yay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseBindings(string(contents)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// Measured values of one analog axis, in raw units.
+// The zero value means the axis is not calibrated.
+type AxisCalibration struct {
+	Center  float64 // Value at rest
+	Noise   float64 // Largest distance from Center seen at rest
+	Min     float64
+	Max     float64
+}
+
+func (axis AxisCalibration) IsCalibrated() bool {
+	return axis.Max > axis.Min
+}
+
+// Returns a thumbstick axis as a fraction between -1.0 and 1.0.
+func (axis AxisCalibration) Stick(value SHORT) float64 {
+	const MaxMagnitude = 32767 // Max value of a SHORT ie max value of a thumbstick
+	if !axis.IsCalibrated() {
+		return float64(value) / MaxMagnitude
+	}
+	offset := float64(value) - axis.Center
+	if math.Abs(offset) <= axis.Noise {
+		return 0
+	}
+	// Each side is stretched on its own since the centre is rarely in the middle.
+	if offset > 0 {
+		return math.Min(rescale(offset, axis.Noise, axis.Max - axis.Center), 1)
+	}
+	return -math.Min(rescale(-offset, axis.Noise, axis.Center - axis.Min), 1)
+}
+
+// Returns a trigger as a fraction between 0.0 and 1.0.
+func (axis AxisCalibration) Trigger(value BYTE) float64 {
+	const MaxMagnitude = 255 // Max value of a BYTE ie max value of a trigger
+	if !axis.IsCalibrated() {
+		return float64(value) / MaxMagnitude
+	}
+	return rescale(float64(value), axis.Center + axis.Noise, axis.Max)
+}
+
+type Calibration struct {
+	LeftX         AxisCalibration
+	LeftY         AxisCalibration
+	RightX        AxisCalibration
+	RightY        AxisCalibration
+	LeftTrigger   AxisCalibration
+	RightTrigger  AxisCalibration
+}
+
+// Returns the gamepad with the calibration applied, still in raw units, for
+// when gamepads are combined before the bindings see them. Uncalibrated axes
+// are left as they are.
+func (c Calibration) Apply(gamepad XInputGamepad) XInputGamepad {
+	stick := func(axis AxisCalibration, value SHORT) SHORT {
+		return SHORT(math.Round(axis.Stick(value) * 32767))
+	}
+	trigger := func(axis AxisCalibration, value BYTE) BYTE {
+		return BYTE(math.Round(axis.Trigger(value) * 255))
+	}
+	gamepad.ThumbLX = stick(c.LeftX, gamepad.ThumbLX)
+	gamepad.ThumbLY = stick(c.LeftY, gamepad.ThumbLY)
+	gamepad.ThumbRX = stick(c.RightX, gamepad.ThumbRX)
+	gamepad.ThumbRY = stick(c.RightY, gamepad.ThumbRY)
+	gamepad.LeftTrigger = trigger(c.LeftTrigger, gamepad.LeftTrigger)
+	gamepad.RightTrigger = trigger(c.RightTrigger, gamepad.RightTrigger)
+	return gamepad
+}
+
+// Calibration files are named after the controller slot, 0 to 3.
+func CalibrationPath(userIndex int) string {
+	return fmt.Sprintf("calibration%d.yay", userIndex)
+}
+
+func (c *Calibration) axes() map[string]*AxisCalibration {
+	return map[string]*AxisCalibration {
+		"LEFTX"        : &c.LeftX,
+		"LEFTY"        : &c.LeftY,
+		"RIGHTX"       : &c.RightX,
+		"RIGHTY"       : &c.RightY,
+		"LEFTTRIGGER"  : &c.LeftTrigger,
+		"RIGHTTRIGGER" : &c.RightTrigger,
+	}
+}
+
+// Each line is an axis followed by its centre, noise, min and max, eg
+// "LEFT_X = 120 300 -32100 32767".
+func ParseCalibration(contents string) (Calibration, error) {
+	calibration := Calibration{}
+	axes := calibration.axes()
+	file, err := ParseYay(contents)
+	if err != nil {
+		return calibration, err
+	}
+	for _, statement := range(file.Statements) {
+		axis, found := axes[statement.Lhs[0].Word()]
+		if !found || len(statement.Lhs) > 1 {
+			return calibration, statement.Lhs[0].Errorf("unknown axis.")
+		}
+		if len(statement.Rhs) > 4 {
+			return calibration, statement.Rhs[4].Errorf("expected centre, noise, min and max.")
+		} else if len(statement.Rhs) < 4 {
+			return calibration, statement.Rhs[0].Errorf("expected centre, noise, min and max.")
+		}
+		var numbers [4]float64
+		for i, token := range(statement.Rhs) {
+			number, err := parseNumber(token)
+			if err != nil {
+				return calibration, err
+			}
+			numbers[i] = number
+		}
+		*axis = AxisCalibration{numbers[0], numbers[1], numbers[2], numbers[3]}
+	}
+	return calibration, nil
+}
+
+// Returns an empty calibration if the controller has never been calibrated.
+func LoadCalibration(userIndex int) (Calibration, error) {
+	bytes, err := ioutil.ReadFile(CalibrationPath(userIndex))
+	if os.IsNotExist(err) {
+		return Calibration{}, nil
+	} else if err != nil {
+		return Calibration{}, err
+	}
+	return ParseCalibration(string(bytes))
+}
+
+func (c Calibration) String() string {
+	var s strings.Builder
+	s.WriteString("# Written by yaypad calibrate. Centre, noise, min and max of each axis.\n")
+	write := func(name string, axis AxisCalibration) {
+		s.WriteString(fmt.Sprintf("%s = %.0f %.0f %.0f %.0f\n", name, axis.Center, axis.Noise, axis.Min, axis.Max))
+	}
+	write("LEFT_X", c.LeftX)
+	write("LEFT_Y", c.LeftY)
+	write("RIGHT_X", c.RightX)
+	write("RIGHT_Y", c.RightY)
+	write("LEFT_TRIGGER", c.LeftTrigger)
+	write("RIGHT_TRIGGER", c.RightTrigger)
+	return s.String()
+}
+
+// Collects raw samples of every analog axis.
+type calibrationSamples struct {
+	values  [6][]float64
+}
+
+func (samples *calibrationSamples) add(gamepad XInputGamepad) {
+	raw := [6]float64{
+		float64(gamepad.ThumbLX), float64(gamepad.ThumbLY),
+		float64(gamepad.ThumbRX), float64(gamepad.ThumbRY),
+		float64(gamepad.LeftTrigger), float64(gamepad.RightTrigger),
+	}
+	for i, value := range(raw) {
+		samples.values[i] = append(samples.values[i], value)
+	}
+}
+
+// Builds a calibration from samples taken at rest and samples taken while
+// moving every axis to its limits. An axis whose sweep doesn't reach well past
+// its noise is left uncalibrated, since rescaling it would turn the slightest
+// movement into full deflection. Returns the names of those axes.
+func measureCalibration(rest, sweep calibrationSamples) (Calibration, []string) {
+	// Travel needed past the noise, a quarter of each side of a stick or of a trigger.
+	margins := [6]float64{32767 / 4, 32767 / 4, 32767 / 4, 32767 / 4, 255 / 4, 255 / 4}
+	names := [6]string{"LEFT_X", "LEFT_Y", "RIGHT_X", "RIGHT_Y", "LEFT_TRIGGER", "RIGHT_TRIGGER"}
+	var axes [6]AxisCalibration
+	uncalibrated := []string{}
+	for i := range(axes) {
+		axis := &axes[i]
+		for _, value := range(rest.values[i]) {
+			axis.Center += value
+		}
+		axis.Center /= float64(len(rest.values[i]))
+		axis.Min, axis.Max = axis.Center, axis.Center
+		for _, value := range(rest.values[i]) {
+			axis.Noise = math.Max(axis.Noise, math.Abs(value - axis.Center))
+		}
+		for _, value := range(append(rest.values[i], sweep.values[i]...)) {
+			axis.Min = math.Min(axis.Min, value)
+			axis.Max = math.Max(axis.Max, value)
+		}
+		// Triggers rest at one end so only their upper side is checked.
+		isTrigger := i >= 4
+		if axis.Max - axis.Center <= axis.Noise + margins[i] ||
+		   !isTrigger && axis.Center - axis.Min <= axis.Noise + margins[i] {
+			*axis = AxisCalibration{}
+			uncalibrated = append(uncalibrated, names[i])
+		}
+	}
+	return Calibration{axes[0], axes[1], axes[2], axes[3], axes[4], axes[5]}, uncalibrated
+}
+
+// Walks the user through calibrating a controller and writes the calibration file.
+func RunCalibration(userIndex int) error {
+	const RestTime = 3 * time.Second
+	stdin := bufio.NewReader(os.Stdin)
+
+	fmt.Printf("Calibrating controller %d.\n", userIndex)
+	fmt.Println("Leave both thumbsticks centred and the triggers released, then press Enter.")
+	stdin.ReadString('\n')
+	rest := calibrationSamples{}
+	for start := time.Now(); time.Since(start) < RestTime; {
+		state, found := getGamepadState(userIndex)
+		if !found {
+			return fmt.Errorf("controller %d is not connected.", userIndex)
+		}
+		rest.add(state.Gamepad)
+		time.Sleep(ConnectedPollTime)
+	}
+
+	fmt.Println("Rotate both thumbsticks around their rims a few times and pull both triggers")
+	fmt.Println("all the way, then press Enter.")
+	done := make(chan bool, 1)
+	go func() {
+		stdin.ReadString('\n')
+		done <- true
+	}()
+	sweep := calibrationSamples{}
+	for sampling := true; sampling; {
+		select {
+			case <-done:
+				sampling = false
+			default:
+				state, found := getGamepadState(userIndex)
+				if !found {
+					return fmt.Errorf("controller %d is not connected.", userIndex)
+				}
+				sweep.add(state.Gamepad)
+				time.Sleep(ConnectedPollTime)
+		}
+	}
+
+	calibration, uncalibrated := measureCalibration(rest, sweep)
+	for _, name := range(uncalibrated) {
+		fmt.Printf("Warning: %s barely moved so it is left uncalibrated. Calibrate again to fix it.\n", name)
+	}
+	path := CalibrationPath(userIndex)
+	err := ioutil.WriteFile(path, []byte(calibration.String()), 0644)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s.\n", path)
+	return nil
+}
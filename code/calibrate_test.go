@@ -0,0 +1,86 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Samples where every stick axis takes the values in stick and both triggers
+// those in trigger.
+func samplesOf(stick []SHORT, trigger []BYTE) calibrationSamples {
+	samples := calibrationSamples{}
+	for i := range(stick) {
+		samples.add(XInputGamepad{
+			ThumbLX: stick[i], ThumbLY: stick[i], ThumbRX: stick[i], ThumbRY: stick[i],
+			LeftTrigger: trigger[i], RightTrigger: trigger[i],
+		})
+	}
+	return samples
+}
+
+func TestMeasureCalibration(t *testing.T) {
+	tests := []struct {
+		name          string
+		rest          calibrationSamples
+		sweep         calibrationSamples
+		stick         AxisCalibration
+		trigger       AxisCalibration
+		uncalibrated  int
+	}{
+		{"full sweep",
+			samplesOf([]SHORT{90, 110}, []BYTE{0, 4}),
+			samplesOf([]SHORT{-32000, 32767}, []BYTE{255, 250}),
+			AxisCalibration{100, 10, -32000, 32767},
+			AxisCalibration{2, 2, 0, 255},
+			0},
+		{"not swept",
+			samplesOf([]SHORT{90, 110}, []BYTE{0, 3}),
+			samplesOf([]SHORT{105, 95}, []BYTE{1, 2}),
+			AxisCalibration{},
+			AxisCalibration{},
+			6},
+		{"touched",
+			samplesOf([]SHORT{90, 110}, []BYTE{0, 3}),
+			samplesOf([]SHORT{2000, -1500}, []BYTE{40, 10}),
+			AxisCalibration{},
+			AxisCalibration{},
+			6},
+		{"one side of the stick",
+			samplesOf([]SHORT{90, 110}, []BYTE{0, 4}),
+			samplesOf([]SHORT{32767, 0}, []BYTE{255, 0}),
+			AxisCalibration{},
+			AxisCalibration{2, 2, 0, 255},
+			4},
+		{"no sweep at all",
+			samplesOf([]SHORT{90, 110}, []BYTE{0, 4}),
+			calibrationSamples{},
+			AxisCalibration{},
+			AxisCalibration{},
+			6},
+	}
+	for _, test := range(tests) {
+		calibration, uncalibrated := measureCalibration(test.rest, test.sweep)
+		if calibration.LeftX != test.stick || calibration.RightY != test.stick {
+			t.Errorf("%s: stick = %v and %v, want %v", test.name, calibration.LeftX, calibration.RightY, test.stick)
+		}
+		if calibration.LeftTrigger != test.trigger || calibration.RightTrigger != test.trigger {
+			t.Errorf("%s: triggers = %v and %v, want %v", test.name, calibration.LeftTrigger, calibration.RightTrigger, test.trigger)
+		}
+		if len(uncalibrated) != test.uncalibrated {
+			t.Errorf("%s: uncalibrated axes = %v, want %d of them", test.name, uncalibrated, test.uncalibrated)
+		}
+	}
+}
+
+// An uncalibrated trigger reads raw, so a trigger that was only touched
+// during calibration doesn't read as fully pulled past its noise.
+func TestMeasureCalibrationUntouchedTrigger(t *testing.T) {
+	calibration, uncalibrated := measureCalibration(samplesOf([]SHORT{0, 0}, []BYTE{0, 3}), samplesOf([]SHORT{0}, []BYTE{3}))
+	want := []string{"LEFT_X", "LEFT_Y", "RIGHT_X", "RIGHT_Y", "LEFT_TRIGGER", "RIGHT_TRIGGER"}
+	if !reflect.DeepEqual(uncalibrated, want) {
+		t.Errorf("uncalibrated axes = %v, want %v", uncalibrated, want)
+	}
+	if got := calibration.LeftTrigger.Trigger(4); got > 0.05 {
+		t.Errorf("Trigger(4) = %v, want about 4 / 255", got)
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// Settings used to turn raw gamepad values into normalized ones.
+// Every controller gets its own copy so they can be tuned separately.
+type ProcessingConfig struct {
+	LeftDeadZone           DeadZone
+	RightDeadZone          DeadZone
+	TriggerThreshold       float64 // Must be between 0.0 and 1.0
+	LeftStickCurve         ResponseCurve
+	RightStickCurve        ResponseCurve
+	LeftStickTransform     StickTransform
+	RightStickTransform    StickTransform
+	LeftTriggerTransform   TriggerTransform
+	RightTriggerTransform  TriggerTransform
+	LeftStickFilter        FilterSettings
+	RightStickFilter       FilterSettings
+	LeftTriggerFilter      FilterSettings
+	RightTriggerFilter     FilterSettings
+	LeftStickSnap          AngleSnap // Only applies to mouse movement
+	RightStickSnap         AngleSnap
+	Hysteresis             Hysteresis
+	SOCD                   SOCDMode // For the D-pad and the keys of stick bindings
+	Debounce               DebounceSettings
+	Calibration            Calibration
+}
+
+func NewProcessingConfig() ProcessingConfig {
+	config := ProcessingConfig{}
+	config.LeftDeadZone = NewDeadZone()
+	config.RightDeadZone = NewDeadZone()
+	config.TriggerThreshold = DefaultTriggerThreshold
+	config.LeftStickCurve = NewPowerCurve(1)
+	config.RightStickCurve = NewPowerCurve(1)
+	config.LeftStickTransform = NewStickTransform()
+	config.RightStickTransform = NewStickTransform()
+	config.LeftTriggerTransform = NewTriggerTransform()
+	config.RightTriggerTransform = NewTriggerTransform()
+	config.LeftStickSnap = NewAngleSnap()
+	config.RightStickSnap = NewAngleSnap()
+	return config
+}
+
+// Applies the binding's own curve, or the thumbstick's if it has none.
+// Buttons and triggers without a curve are left alone.
+func (config *ProcessingConfig) ApplyCurve(input GamepadInput, value float32) float32 {
+	if input.Curve != nil {
+		return input.Curve.Evaluate(value)
+	} else if input.IsThumbstick && input.IsLeft {
+		return config.LeftStickCurve.Evaluate(value)
+	} else if input.IsThumbstick {
+		return config.RightStickCurve.Evaluate(value)
+	}
+	return value
+}
+
+// Uses the binding's own hysteresis, or the global one if it has none.
+func (config *ProcessingConfig) IsPressed(input GamepadInput, value float32, wasPressed bool) bool {
+	if input.Hysteresis != nil {
+		return input.Hysteresis.IsPressed(value, wasPressed)
+	}
+	return config.Hysteresis.IsPressed(value, wasPressed)
+}
+
+// Decides when an analog input bound to a key or mouse button counts as
+// pressed. An input is pressed once it goes above Press and stays pressed until
+// it drops to Release or below, so a value hovering at one of them doesn't
+// chatter. The zero value presses on anything but 0.
+type Hysteresis struct {
+	Press    float64 // Between 0.0 and 1.0
+	Release  float64 // Between 0.0 and Press
+}
+
+// value is a normalized input value before the response curve, its sign is ignored.
+func (h Hysteresis) IsPressed(value float32, wasPressed bool) bool {
+	magnitude := math.Abs(float64(value))
+	if wasPressed {
+		return magnitude > h.Release
+	}
+	return magnitude > h.Press
+}
+
+// The same gap between pressing and releasing, moved to press past press.
+// For thresholds other than the binding's own, like a stick's outer ring.
+func (h Hysteresis) At(press float64) Hysteresis {
+	return Hysteresis{press, math.Max(0, press - (h.Press - h.Release))}
+}
+
+// Everything needed to turn one gamepad's input into mouse and keyboard input.
+type Controller struct {
+	UserIndex  int
+	Config     ProcessingConfig
+	Bindings   *Bindings
+	Output     *OutputScheduler // Run it in a goroutine
+	Debouncer  Debouncer
+	Dwell      *DwellClicker // nil unless dwell clicking is on
+
+	filters    FilterBank
+	leftSnap   angleSnapper
+	rightSnap  angleSnapper
+	pressed    map[*GamepadInput]bool
+	dpad       SOCDCleaner
+	sticky     *StickyModifiers
+	router     *outputRouter
+	buttons    WORD // Held buttons as the sequences last saw them
+	previous   GamepadState
+}
+
+func NewController(userIndex int, bindings *Bindings) *Controller {
+	c := &Controller{}
+	c.UserIndex = userIndex
+	c.Config = bindings.Config
+	c.Bindings = bindings
+	c.sticky = NewStickyModifiers()
+	c.sticky.Enabled = bindings.StickyModifiers
+	c.sticky.DoublePress = bindings.StickyDoublePress
+	c.router = &outputRouter{NewKeySOCD(c.Config.SOCD), c.sticky}
+	for _, stick := range(bindings.StickBindings) {
+		c.router.keys.AddPair(stick.Keys[1], stick.Keys[3], false) // Left and right
+		c.router.keys.AddPair(stick.Keys[2], stick.Keys[0], true) // Down and up
+	}
+	c.Output = NewOutputScheduler(bindings, c.router)
+	for _, mouse := range(bindings.StickMice) {
+		c.Output.AddMover(mouse)
+	}
+	c.filters = NewFilterBank(c.Config)
+	c.leftSnap = angleSnapper{AngleSnap: c.Config.LeftStickSnap}
+	c.rightSnap = angleSnapper{AngleSnap: c.Config.RightStickSnap}
+	c.pressed = map[*GamepadInput]bool{}
+	c.Debouncer = NewDebouncer(c.Config.Debounce)
+	if bindings.DwellEnabled {
+		dwell := bindings.Dwell
+		c.Dwell = &dwell
+	}
+	c.dpad = NewSOCDCleaner(c.Config.SOCD)
+	c.previous = NewGamepadState(XInputState{}, &c.Config)
+	return c
+}
+
+// Intended to be called from GamepadPollCallback. Filters need every sample,
+// so this runs the filters and then updates the bindings if anything changed.
+func (c *Controller) Poll(raw XInputState, now time.Time) {
+	filtered := raw
+	filtered.Gamepad = c.filters.Apply(raw.Gamepad, now)
+	filtered.Gamepad.Buttons = c.Debouncer.Apply(filtered.Gamepad.Buttons, now)
+	filtered.Gamepad.Buttons = c.dpad.Buttons(filtered.Gamepad.Buttons)
+	pressed := filtered.Gamepad.Buttons &^ c.buttons
+	filtered.Gamepad.Buttons = c.updateSequences(filtered.Gamepad.Buttons, now)
+	if c.Dwell != nil {
+		if x, y, found := cursorPosition(); found {
+			c.Dwell.Update(c.UserIndex, x, y, pressed, now, c.router)
+		}
+	}
+	if filtered.PacketNumber != c.previous.PacketNumber || filtered.Gamepad != c.previous.Gamepad {
+		c.Update(filtered, now)
+	}
+}
+
+// Fires the button sequences that are done and returns the buttons with the
+// swallowed ones taken out.
+func (c *Controller) updateSequences(buttons WORD, now time.Time) WORD {
+	pressed := buttons &^ c.buttons
+	c.buttons = buttons
+	var swallowed WORD
+	for _, seq := range(c.Bindings.Sequences) {
+		if seq.Update(pressed, buttons, now) {
+			c.router.Tap(seq.Output)
+		}
+		swallowed |= seq.Swallowed()
+	}
+	return buttons &^ swallowed
+}
+
+// Updates the bindings with a new state.
+func (c *Controller) Update(raw XInputState, now time.Time) {
+	state := NewGamepadState(raw, &c.Config)
+	mouseSpeed := c.Bindings.MouseSpeed * c.Bindings.MouseSensitivity
+	var velocityX, velocityY, scrollSpeed float64
+	leftX, leftY := c.leftSnap.Apply(state.LeftThumbstick())
+	rightX, rightY := c.rightSnap.Apply(state.RightThumbstick())
+	snapped := func(input GamepadInput) float32 {
+		if input.IsLeft && input.IsX {
+			return leftX
+		} else if input.IsLeft {
+			return leftY
+		} else if input.IsX {
+			return rightX
+		}
+		return rightY
+	}
+	for in, out := range(c.Bindings.Bindings) {
+		deflection := state.InputValueFloat(*in)
+		value := float64(c.Config.ApplyCurve(*in, deflection))
+		wasPressed := c.pressed[in]
+		pressed := value != 0 && c.Config.IsPressed(*in, deflection, wasPressed)
+		if in.Rapid != nil && in.IsLeft {
+			pressed = in.Rapid.Update(raw.Gamepad.LeftTrigger)
+		} else if in.Rapid != nil {
+			pressed = in.Rapid.Update(raw.Gamepad.RightTrigger)
+		}
+		c.pressed[in] = pressed
+		if out.IsMouseMove {
+			if in.IsThumbstick {
+				value = float64(c.Config.ApplyCurve(*in, snapped(*in)))
+			}
+			if !out.IsAxis {
+				value = math.Abs(value)
+			}
+			velocityX += float64(out.X) * value * mouseSpeed
+			velocityY += float64(out.Y) * value * mouseSpeed
+		} else if out.IsScroll {
+			notches := float64(LONG(out.Value)) / WHEEL_DELTA
+			scrollSpeed += notches * math.Abs(value) * c.Bindings.ScrollSpeed
+		} else if c.sticky.Enabled && out.IsKeyboard && IsModifierKey(WORD(out.Value)) {
+			if pressed && !wasPressed {
+				c.sticky.Press(WORD(out.Value), now)
+			}
+		} else if in.Pulse > 0 {
+			c.Output.Pulse(out, math.Abs(value), in.Pulse, now)
+		} else if out.FiresOnce() {
+			if pressed && !wasPressed {
+				c.router.Send(out)
+			}
+		} else if pressed && !wasPressed {
+			c.Output.Press(out, in.Turbo, now)
+		} else if !pressed && wasPressed {
+			c.Output.Release(out)
+		}
+	}
+	c.Output.SetMotion(velocityX, velocityY, scrollSpeed)
+
+	for _, stick := range(c.Bindings.StickBindings) {
+		stick.Update(state, c.router.Key)
+	}
+	for _, menu := range(c.Bindings.RadialMenus) {
+		menu.Update(c.UserIndex, state, c.router)
+	}
+	for _, cursor := range(c.Bindings.AbsoluteCursors) {
+		cursor.Update(state)
+	}
+	for _, mouse := range(c.Bindings.StickMice) {
+		var x, y, magnitude float32
+		var curve ResponseCurve
+		if mouse.IsLeft {
+			x, y = leftX, leftY
+			magnitude = state.LeftThumbstickMagnitude()
+			curve = c.Config.LeftStickCurve
+		} else {
+			x, y = rightX, rightY
+			magnitude = state.RightThumbstickMagnitude()
+			curve = c.Config.RightStickCurve
+		}
+		if magnitude > 0 {
+			// The curve shapes the distance from the centre, not each axis.
+			scale := curve.Evaluate(magnitude) / magnitude
+			x, y = x * scale, y * scale
+		}
+		mouse.SetStick(x, y)
+	}
+	for _, flick := range(c.Bindings.FlickSticks) {
+		var x, y float32
+		if flick.IsLeft {
+			x, y = state.LeftThumbstick()
+		} else {
+			x, y = state.RightThumbstick()
+		}
+		flickCounts, turnCounts := flick.Update(x, y)
+		if flickCounts != 0 {
+			c.Output.AddBurst(flickCounts, 0, flick.FlickTime)
+		}
+		if turnCounts != 0 {
+			c.Output.AddMotion(turnCounts, 0)
+		}
+	}
+	for _, gesture := range(c.Bindings.Gestures) {
+		var x, y float32
+		if gesture.IsLeft {
+			x, y = state.LeftThumbstick()
+		} else {
+			x, y = state.RightThumbstick()
+		}
+		if gesture.Update(x, y, now) {
+			c.router.Tap(gesture.Output)
+		}
+	}
+	c.previous = state
+}
+
+// Sends one controller's keys and mouse buttons. Direction keys go through the
+// controller's own SOCD cleaning and letting go of anything but a modifier
+// lets go of its latched sticky modifiers.
+type outputRouter struct {
+	keys    *KeySOCD
+	sticky  *StickyModifiers
+}
+
+// Presses the key if down is set and lets go of it otherwise.
+func (r *outputRouter) Key(key WORD, down bool) {
+	cleaned := r.keys.Filter(key, down)
+	if !cleaned && down {
+		sendKeyDownInput(key)
+	} else if !cleaned {
+		sendKeyUpInput(key)
+	}
+	if !down && !IsModifierKey(key) {
+		r.outputDone()
+	}
+}
+
+func (r *outputRouter) Send(out *MouseOrKeyboardInput) {
+	if out.IsKeyboard {
+		r.Key(WORD(out.Value), true)
+	} else {
+		out.Send()
+	}
+}
+
+func (r *outputRouter) Release(out *MouseOrKeyboardInput) {
+	if out.IsKeyboard {
+		r.Key(WORD(out.Value), false)
+	} else {
+		out.Release()
+		if out.IsMouseButton {
+			r.outputDone()
+		}
+	}
+}
+
+func (r *outputRouter) outputDone() {
+	if r.sticky.Enabled {
+		r.sticky.OutputDone()
+	}
+}
+
+// Presses and releases the output right away.
+func (r *outputRouter) Tap(out *MouseOrKeyboardInput) {
+	r.Send(out)
+	r.Release(out)
+}
+
+// Intended to be called from GamepadDisconnectedCallback.
+func (c *Controller) Disconnect() {
+	for _, stick := range(c.Bindings.StickBindings) {
+		stick.Release(c.router.Key)
+	}
+	for _, menu := range(c.Bindings.RadialMenus) {
+		menu.Close(c.UserIndex)
+	}
+	for _, flick := range(c.Bindings.FlickSticks) {
+		flick.Reset()
+	}
+	for _, mouse := range(c.Bindings.StickMice) {
+		mouse.Reset()
+	}
+	for _, gesture := range(c.Bindings.Gestures) {
+		gesture.Reset()
+	}
+	for _, seq := range(c.Bindings.Sequences) {
+		seq.Reset()
+	}
+	c.buttons = 0
+	if c.Dwell != nil {
+		c.Dwell.Reset(c.router)
+	}
+	c.Output.ReleaseAll()
+	c.sticky.ReleaseAll()
+	c.filters.Reset()
+	c.leftSnap.Reset()
+	c.rightSnap.Reset()
+	c.pressed = map[*GamepadInput]bool{}
+	c.Debouncer.Reset()
+	c.dpad.Reset()
+	for in := range(c.Bindings.Bindings) {
+		if in.Rapid != nil {
+			in.Rapid.Reset()
+		}
+	}
+	c.previous = NewGamepadState(XInputState{}, &c.Config)
+}
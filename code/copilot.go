@@ -0,0 +1,139 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// Copilot priorities, deciding which gamepad's stick or trigger is used
+const (
+	CopilotLargest = iota // Whichever is deflected further
+	CopilotPilot          // The pilot's whenever it is deflected at all
+	CopilotCopilot        // The copilot's whenever it is deflected at all
+)
+
+var StringToCopilotPriority = map[string]int {
+	"LARGEST" : CopilotLargest,
+	"PILOT"   : CopilotPilot,
+	"COPILOT" : CopilotCopilot,
+}
+
+// Combines two gamepads into one, so someone can help a player out.
+// Buttons are pressed if they are pressed on either gamepad. Each stick and
+// trigger comes from one gamepad or the other, decided by Priority. A stick
+// is taken as a whole so its axes never come from different gamepads.
+// The merged gamepad is what the bindings see, under the pilot's slot.
+// Each gamepad is calibrated before merging, so the merged gamepad must not
+// be calibrated again.
+type Copilot struct {
+	Pilot               int // Slot of the player's gamepad, 0-3
+	Copilot             int // Slot of the helper's gamepad, 0-3
+	Priority            int // See the constants prefixed by Copilot
+	Config              *ProcessingConfig // Its dead zones and trigger threshold decide what counts as untouched
+	PilotCalibration    Calibration
+	CopilotCalibration  Calibration
+
+	packet              DWORD // Packet number of the merged gamepad
+	previous            [2]DWORD
+}
+
+func NewCopilot(pilot, copilot, priority int, config *ProcessingConfig) Copilot {
+	c := Copilot{}
+	c.Pilot = pilot
+	c.Copilot = copilot
+	c.Priority = priority
+	c.Config = config
+	return c
+}
+
+// Intended usage: Set the callback functions and call this in a goroutine
+// instead of PollGamepad. The callbacks get the pilot's slot. The merged
+// gamepad is connected while either gamepad is.
+func (c *Copilot) Run() {
+	connected := false
+	var previousPacketNumber DWORD = 0
+	for {
+		pilot, pilotFound := getGamepadState(c.Pilot)
+		copilot, copilotFound := getGamepadState(c.Copilot)
+		if !pilotFound && !copilotFound {
+			if connected {
+				GamepadDisconnectedCallback(c.Pilot)
+				connected = false
+			}
+			time.Sleep(DisconnectedPollTime)
+			continue
+		}
+		if !connected {
+			GamepadConnectedCallback(c.Pilot)
+			connected = true
+		}
+		// A missing gamepad counts as one nobody touches.
+		if !pilotFound {
+			pilot = XInputState{}
+		}
+		if !copilotFound {
+			copilot = XInputState{}
+		}
+		state := c.Merge(pilot, copilot)
+		GamepadPollCallback(c.Pilot, state)
+		if previousPacketNumber != state.PacketNumber {
+			GamepadInputCallback(c.Pilot, state)
+			previousPacketNumber = state.PacketNumber
+		}
+		time.Sleep(ConnectedPollTime)
+	}
+}
+
+// The merged packet number changes whenever either gamepad's does.
+func (c *Copilot) Merge(pilot, copilot XInputState) XInputState {
+	if pilot.PacketNumber != c.previous[0] || copilot.PacketNumber != c.previous[1] {
+		c.packet++
+		c.previous = [2]DWORD{pilot.PacketNumber, copilot.PacketNumber}
+	}
+	return XInputState{c.packet, c.MergeGamepads(c.PilotCalibration.Apply(pilot.Gamepad), c.CopilotCalibration.Apply(copilot.Gamepad))}
+}
+
+// Takes calibrated gamepads.
+func (c *Copilot) MergeGamepads(pilot, copilot XInputGamepad) XInputGamepad {
+	const MaxStick = 32767
+	const MaxTrigger = 255
+	stickMagnitude := func(x, y SHORT) float64 {
+		return math.Hypot(float64(x), float64(y)) / MaxStick
+	}
+
+	merged := XInputGamepad{}
+	merged.Buttons = pilot.Buttons | copilot.Buttons
+	if c.pilotWins(float64(pilot.LeftTrigger) / MaxTrigger, float64(copilot.LeftTrigger) / MaxTrigger, c.Config.TriggerThreshold) {
+		merged.LeftTrigger = pilot.LeftTrigger
+	} else {
+		merged.LeftTrigger = copilot.LeftTrigger
+	}
+	if c.pilotWins(float64(pilot.RightTrigger) / MaxTrigger, float64(copilot.RightTrigger) / MaxTrigger, c.Config.TriggerThreshold) {
+		merged.RightTrigger = pilot.RightTrigger
+	} else {
+		merged.RightTrigger = copilot.RightTrigger
+	}
+	if c.pilotWins(stickMagnitude(pilot.ThumbLX, pilot.ThumbLY), stickMagnitude(copilot.ThumbLX, copilot.ThumbLY), c.Config.LeftDeadZone.Inner) {
+		merged.ThumbLX, merged.ThumbLY = pilot.ThumbLX, pilot.ThumbLY
+	} else {
+		merged.ThumbLX, merged.ThumbLY = copilot.ThumbLX, copilot.ThumbLY
+	}
+	if c.pilotWins(stickMagnitude(pilot.ThumbRX, pilot.ThumbRY), stickMagnitude(copilot.ThumbRX, copilot.ThumbRY), c.Config.RightDeadZone.Inner) {
+		merged.ThumbRX, merged.ThumbRY = pilot.ThumbRX, pilot.ThumbRY
+	} else {
+		merged.ThumbRX, merged.ThumbRY = copilot.ThumbRX, copilot.ThumbRY
+	}
+	return merged
+}
+
+// Takes how far each gamepad's stick or trigger is deflected, from 0.0 to
+// 1.0, and the deflection below which it counts as untouched.
+func (c *Copilot) pilotWins(pilot, copilot, rest float64) bool {
+	switch c.Priority {
+		case CopilotPilot:
+			return pilot > rest || copilot <= rest
+		case CopilotCopilot:
+			return copilot <= rest
+	}
+	return pilot >= copilot
+}
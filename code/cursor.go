@@ -0,0 +1,170 @@
+package main
+
+import (
+	"unsafe"
+)
+
+var syscallGetForegroundWindow = user32.NewProc("GetForegroundWindow");
+var syscallGetWindowRect = user32.NewProc("GetWindowRect");
+var syscallGetSystemMetrics = user32.NewProc("GetSystemMetrics");
+var syscallGetCursorPos = user32.NewProc("GetCursorPos");
+
+// https://docs.microsoft.com/en-us/windows/win32/api/windef/ns-windef-rect
+type Rect struct {
+	Left    LONG
+	Top     LONG
+	Right   LONG
+	Bottom  LONG
+}
+
+// A rectangle given in fractions of the primary screen, where 0.0 0.0 is the
+// top left corner. If IsWindow is set, the foreground window is used instead.
+type CursorArea struct {
+	Left      float64
+	Top       float64
+	Right     float64
+	Bottom    float64
+	IsWindow  bool
+}
+
+func NewScreenArea() CursorArea {
+	return CursorArea{0, 0, 1, 1, false}
+}
+
+// Returns the area in fractions of the primary screen.
+func (area CursorArea) Resolve() (float64, float64, float64, float64) {
+	if !area.IsWindow {
+		return area.Left, area.Top, area.Right, area.Bottom
+	}
+	window, _, _ := syscallGetForegroundWindow.Call()
+	if window == 0 {
+		return 0, 0, 1, 1
+	}
+	var rect Rect
+	ok, _, _ := syscallGetWindowRect.Call(window, uintptr(unsafe.Pointer(&rect)))
+	width, height := screenSize()
+	if ok == 0 || width == 0 || height == 0 {
+		return 0, 0, 1, 1
+	}
+	return float64(rect.Left) / width, float64(rect.Top) / height,
+		float64(rect.Right) / width, float64(rect.Bottom) / height
+}
+
+// Returns the size of the primary screen in pixels.
+func screenSize() (float64, float64) {
+	width, _, _ := syscallGetSystemMetrics.Call(SM_CXSCREEN)
+	height, _, _ := syscallGetSystemMetrics.Call(SM_CYSCREEN)
+	return float64(width), float64(height)
+}
+
+// Returns the cursor position in pixels.
+func cursorPosition() (LONG, LONG, bool) {
+	// https://docs.microsoft.com/en-us/windows/win32/api/windef/ns-windef-point
+	var point struct {
+		X  LONG
+		Y  LONG
+	}
+	ok, _, _ := syscallGetCursorPos.Call(uintptr(unsafe.Pointer(&point)))
+	return point.X, point.Y, ok != 0
+}
+
+// Converts a fraction of the screen into the 0 to 65535 range used by
+// MOUSEEVENTF_ABSOLUTE.
+func toAbsoluteCoordinate(fraction float64) LONG {
+	if fraction < 0 {
+		fraction = 0
+	} else if fraction > 1 {
+		fraction = 1
+	}
+	return LONG(fraction * 65535)
+}
+
+// Maps a thumbstick's position directly onto an area of the screen.
+// When the stick is let go the cursor jumps to the anchor, which is given in
+// fractions of the area.
+type AbsoluteCursor struct {
+	IsLeft   bool
+	Area     CursorArea
+	AnchorX  float64
+	AnchorY  float64
+
+	active   bool
+}
+
+func NewAbsoluteCursor(isLeft bool) AbsoluteCursor {
+	cursor := AbsoluteCursor{}
+	cursor.IsLeft = isLeft
+	cursor.Area = NewScreenArea()
+	cursor.AnchorX = 0.5
+	cursor.AnchorY = 0.5
+	return cursor
+}
+
+func (cursor *AbsoluteCursor) Update(state GamepadState) {
+	var x, y float32
+	if cursor.IsLeft {
+		x, y = state.LeftThumbstick()
+	} else {
+		x, y = state.RightThumbstick()
+	}
+	if x == 0 && y == 0 {
+		if cursor.active {
+			cursor.active = false
+			cursor.moveTo(cursor.AnchorX, cursor.AnchorY)
+		}
+		return
+	}
+	cursor.active = true
+	// The stick's Y axis points up while the screen's points down.
+	cursor.moveTo((float64(x) + 1) / 2, (1 - float64(y)) / 2)
+}
+
+// Moves the cursor to a point given in fractions of the area.
+func (cursor *AbsoluteCursor) moveTo(fx, fy float64) {
+	left, top, right, bottom := cursor.Area.Resolve()
+	x := left + fx * (right - left)
+	y := top + fy * (bottom - top)
+	sendAbsoluteMoveInput(toAbsoluteCoordinate(x), toAbsoluteCoordinate(y))
+}
+
+// A point on the virtual desktop in fractions, where 0.0 0.0 is the top left
+// corner, and an optional mouse button to click once there.
+type WarpPoint struct {
+	X         float64
+	Y         float64
+	Click     int // A value prefixed by VK_, or 0 for no click.
+}
+
+// Jumps the cursor to the next point each time it fires.
+type Warp struct {
+	Points  []WarpPoint
+	next    int
+}
+
+var StringToWarpPoint = map[string]WarpPoint {
+	"CENTER"      : {0.5, 0.5, 0},
+	"CENTRE"      : {0.5, 0.5, 0},
+	"TOP"         : {0.5, 0.0, 0},
+	"BOTTOM"      : {0.5, 1.0, 0},
+	"LEFT"        : {0.0, 0.5, 0},
+	"RIGHT"       : {1.0, 0.5, 0},
+	"TOPLEFT"     : {0.0, 0.0, 0},
+	"TOPRIGHT"    : {1.0, 0.0, 0},
+	"BOTTOMLEFT"  : {0.0, 1.0, 0},
+	"BOTTOMRIGHT" : {1.0, 1.0, 0},
+}
+
+func (warp *Warp) Fire() {
+	point := warp.Points[warp.next]
+	warp.next = (warp.next + 1) % len(warp.Points)
+	sendVirtualDeskMoveInput(toAbsoluteCoordinate(point.X), toAbsoluteCoordinate(point.Y))
+	if point.Click != 0 {
+		sendMouseClickInput(point.Click)
+	}
+}
+
+const (
+	// For use in GetSystemMetrics
+	SM_CXSCREEN = 0
+	SM_CYSCREEN = 1
+)
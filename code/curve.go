@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// Response curve kinds
+const (
+	Constant = iota // Any deflection counts as full deflection.
+	Power           // |v| raised to Exponent. Linear, squared and cubed are 1, 2 and 3.
+	Piecewise       // Straight lines between Points.
+	SCurve          // Slow near the centre and the rim, fast in between. Steeper with a higher Exponent.
+)
+
+type CurvePoint struct {
+	In   float64
+	Out  float64
+}
+
+// Reshapes a normalized analog value. Curves work on the absolute value so
+// the sign of the input is kept.
+type ResponseCurve struct {
+	Kind      int
+	Exponent  float64 // Used by Power and SCurve.
+	Points    []CurvePoint // Used by Piecewise. Sorted by In.
+}
+
+func NewPowerCurve(exponent float64) ResponseCurve {
+	return ResponseCurve{Kind: Power, Exponent: exponent}
+}
+
+func NewSCurve(exponent float64) ResponseCurve {
+	return ResponseCurve{Kind: SCurve, Exponent: exponent}
+}
+
+// The curve always passes through 0 0 and 1 1, so those points may be left out.
+func NewPiecewiseCurve(points []CurvePoint) ResponseCurve {
+	sorted := append([]CurvePoint{}, points...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].In < sorted[j].In })
+	if len(sorted) == 0 || sorted[0].In > 0 {
+		sorted = append([]CurvePoint{{0, 0}}, sorted...)
+	}
+	if sorted[len(sorted)-1].In < 1 {
+		sorted = append(sorted, CurvePoint{1, 1})
+	}
+	return ResponseCurve{Kind: Piecewise, Points: sorted}
+}
+
+// value is between -1.0 and 1.0 and so is the return value.
+func (curve ResponseCurve) Evaluate(value float32) float32 {
+	if value == 0 {
+		return 0
+	}
+	sign := float32(1)
+	if value < 0 {
+		sign = -1
+	}
+	v := math.Min(math.Abs(float64(value)), 1)
+
+	var out float64
+	switch curve.Kind {
+		case Constant:
+			out = 1
+		case Power:
+			out = math.Pow(v, curve.Exponent)
+		case SCurve:
+			a := math.Pow(v, curve.Exponent)
+			b := math.Pow(1 - v, curve.Exponent)
+			out = a / (a + b)
+		case Piecewise:
+			out = curve.piecewise(v)
+		default:
+			panic("Internal error: unknown curve kind")
+	}
+	return sign * float32(math.Max(0, math.Min(out, 1)))
+}
+
+func (curve ResponseCurve) piecewise(v float64) float64 {
+	points := curve.Points
+	if v <= points[0].In {
+		return points[0].Out
+	}
+	for i := 1; i < len(points); i++ {
+		if v <= points[i].In {
+			a, b := points[i-1], points[i]
+			if b.In == a.In {
+				return b.Out
+			}
+			t := (v - a.In) / (b.In - a.In)
+			return a.Out + t * (b.Out - a.Out)
+		}
+	}
+	return points[len(points)-1].Out
+}
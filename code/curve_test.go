@@ -0,0 +1,64 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResponseCurveEvaluate(t *testing.T) {
+	tests := []struct {
+		name   string
+		curve  ResponseCurve
+		in     float32
+		want   float32
+	}{
+		{"linear", NewPowerCurve(1), 0.3, 0.3},
+		{"squared", NewPowerCurve(2), 0.5, 0.25},
+		{"cubed", NewPowerCurve(3), 0.5, 0.125},
+		{"square root", NewPowerCurve(0.5), 0.25, 0.5},
+		{"power at rim", NewPowerCurve(2), 1, 1},
+		{"constant", ResponseCurve{Kind: Constant}, 0.1, 1},
+		{"s-curve centre", NewSCurve(2), 0.5, 0.5},
+		{"s-curve slow start", NewSCurve(2), 0.25, 0.1},
+		{"s-curve slow end", NewSCurve(2), 0.75, 0.9},
+		{"s-curve at rim", NewSCurve(2), 1, 1},
+		{"piecewise implied ends", NewPiecewiseCurve([]CurvePoint{{0.5, 0.2}}), 0.25, 0.1},
+		{"piecewise between points", NewPiecewiseCurve([]CurvePoint{{0.5, 0.2}}), 0.75, 0.6},
+		{"piecewise on a point", NewPiecewiseCurve([]CurvePoint{{0.5, 0.2}}), 0.5, 0.2},
+		{"piecewise unsorted", NewPiecewiseCurve([]CurvePoint{{0.8, 0.9}, {0.2, 0.1}}), 0.5, 0.5},
+		{"piecewise point at 0", NewPiecewiseCurve([]CurvePoint{{0, 0.2}}), 0.01, 0.208},
+		{"piecewise point at 1", NewPiecewiseCurve([]CurvePoint{{1, 0.5}}), 0.5, 0.25},
+		{"piecewise ends at point at 1", NewPiecewiseCurve([]CurvePoint{{1, 0.5}}), 1, 0.5},
+		{"negative power", NewPowerCurve(2), -0.5, -0.25},
+		{"negative s-curve", NewSCurve(2), -0.25, -0.1},
+		{"negative piecewise", NewPiecewiseCurve([]CurvePoint{{0.5, 0.2}}), -0.75, -0.6},
+		{"zero stays zero", NewPiecewiseCurve([]CurvePoint{{0, 0.2}}), 0, 0},
+		{"clamp input", NewPowerCurve(2), 1.5, 1},
+		{"clamp negative input", NewPowerCurve(2), -1.5, -1},
+		{"clamp output", NewPiecewiseCurve([]CurvePoint{{0.5, 1.5}}), 0.5, 1},
+		{"clamp negative output", NewPiecewiseCurve([]CurvePoint{{0.5, -0.5}}), 0.5, 0},
+	}
+	for _, test := range(tests) {
+		got := test.curve.Evaluate(test.in)
+		if math.Abs(float64(got - test.want)) > 1e-5 {
+			t.Errorf("%s: Evaluate(%v) = %v, want %v", test.name, test.in, got, test.want)
+		}
+	}
+}
+
+// Curves reshape the distance from the centre, so flipping the input flips
+// the output.
+func TestResponseCurveSymmetry(t *testing.T) {
+	curves := []ResponseCurve{
+		NewPowerCurve(1.5),
+		NewSCurve(3),
+		NewPiecewiseCurve([]CurvePoint{{0.3, 0.1}, {0.7, 0.8}}),
+	}
+	for _, curve := range(curves) {
+		for v := float32(0); v <= 1; v += 0.05 {
+			if curve.Evaluate(-v) != -curve.Evaluate(v) {
+				t.Errorf("curve %v: Evaluate(%v) = %v but Evaluate(%v) = %v", curve, -v, curve.Evaluate(-v), v, curve.Evaluate(v))
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math"
+)
+
+// Dead zone shapes
+// https://github.com/Minimuino/thumbstick-deadzones explains them with pictures.
+const (
+	Axial = iota    // Each axis has its own dead zone. Snaps to the axes but cuts diagonals.
+	Radial          // A circle around the centre. Jumps from zero to the circle's edge.
+	ScaledRadial    // A circle around the centre, rescaled so the output starts at zero.
+	Hybrid          // Scaled radial distance with the bow tie's direction, removing drift near the axes.
+	BowTie          // Each axis' dead zone grows with the other axis' deflection.
+)
+
+// All values are fractions of the thumbstick's full range.
+type DeadZone struct {
+	Shape  int
+	Inner  float64 // Deflection below this is ignored.
+	Outer  float64 // Deflection within this distance of the rim counts as full deflection.
+	Anti   float64 // The smallest non-zero output. Beats the dead zone built into some games.
+}
+
+func NewDeadZone() DeadZone {
+	zone := DeadZone{}
+	zone.Shape = ScaledRadial
+	zone.Inner = DefaultThumbstickDeadZone
+	return zone
+}
+
+// x and y are between -1.0 and 1.0. Returns the x and y values after the
+// dead zone, and the magnitude of those, all between -1.0 and 1.0.
+func (zone DeadZone) Apply(x, y float64) (float64, float64, float64) {
+	magnitude := math.Sqrt(x*x + y*y)
+	if magnitude > 1 {
+		// Due to imperfect hardware the rim can be exceeded
+		x /= magnitude
+		y /= magnitude
+		magnitude = 1
+	}
+	high := 1 - zone.Outer
+
+	switch zone.Shape {
+		case Axial:
+			x = rescaleAxis(x, zone.Inner, high)
+			y = rescaleAxis(y, zone.Inner, high)
+		case Radial:
+			if magnitude < zone.Inner {
+				x, y = 0, 0
+			} else if magnitude >= high {
+				x, y = x / magnitude, y / magnitude
+			}
+		case ScaledRadial:
+			x, y = zone.scaledRadial(x, y, magnitude)
+		case BowTie:
+			x, y = zone.bowTie(x, y)
+		case Hybrid:
+			// The direction comes from the bow tie and the distance from the scaled radial.
+			sx, sy := zone.scaledRadial(x, y, magnitude)
+			bx, by := zone.bowTie(x, y)
+			scaled := math.Sqrt(sx*sx + sy*sy)
+			direction := math.Sqrt(bx*bx + by*by)
+			if scaled == 0 || direction == 0 {
+				x, y = 0, 0
+			} else {
+				x, y = bx / direction * scaled, by / direction * scaled
+			}
+		default:
+			panic("Internal error: unknown dead zone shape")
+	}
+
+	magnitude = math.Sqrt(x*x + y*y)
+	if magnitude == 0 {
+		return 0, 0, 0
+	}
+	scaled := math.Min(magnitude, 1)
+	if zone.Anti > 0 {
+		scaled = zone.Anti + (1 - zone.Anti) * scaled
+	}
+	return x / magnitude * scaled, y / magnitude * scaled, scaled
+}
+
+// This is the method from
+// https://docs.microsoft.com/en-us/windows/win32/xinput/getting-started-with-xinput#dead-zone
+func (zone DeadZone) scaledRadial(x, y, magnitude float64) (float64, float64) {
+	if magnitude <= zone.Inner {
+		return 0, 0
+	}
+	scaled := rescale(magnitude, zone.Inner, 1 - zone.Outer)
+	return x / magnitude * scaled, y / magnitude * scaled
+}
+
+func (zone DeadZone) bowTie(x, y float64) (float64, float64) {
+	high := 1 - zone.Outer
+	return rescaleAxis(x, zone.Inner * math.Abs(y), high), rescaleAxis(y, zone.Inner * math.Abs(x), high)
+}
+
+// Maps low..high onto 0..1, keeping the sign of value.
+func rescaleAxis(value, low, high float64) float64 {
+	if value < 0 {
+		return -rescale(-value, low, high)
+	}
+	return rescale(value, low, high)
+}
+
+// Maps low..high onto 0..1 and clamps.
+func rescale(value, low, high float64) float64 {
+	if value <= low {
+		return 0
+	} else if value >= high || high <= low {
+		return 1
+	}
+	return (value - low) / (high - low)
+}
+
+var StringToDeadZoneShape = map[string]int {
+	"AXIAL"        : Axial,
+	"RADIAL"       : Radial,
+	"SCALEDRADIAL" : ScaledRadial,
+	"HYBRID"       : Hybrid,
+	"BOWTIE"       : BowTie,
+}
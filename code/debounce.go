@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const ButtonCount = 16 // Bits in XInputGamepad.Buttons
+
+// Filters chatter out of worn buttons before the bindings see them.
+// A change goes through right away, and for Window seconds after it
+// the button can't change back. A press only goes through once the button has
+// been held for MinPress seconds. Both are indexed by button bit and 0 turns
+// them off.
+type DebounceSettings struct {
+	Window    [ButtonCount]float64
+	MinPress  [ButtonCount]float64
+}
+
+type Debouncer struct {
+	Settings  DebounceSettings
+	Filtered  [ButtonCount]int // Changes thrown away per button bit, for debugging
+
+	buttons   [ButtonCount]debouncedButton
+}
+
+type debouncedButton struct {
+	raw       bool
+	rawSince  time.Time // When raw last changed
+	reported  bool
+	changed   time.Time // When reported last changed
+}
+
+func NewDebouncer(settings DebounceSettings) Debouncer {
+	return Debouncer{Settings: settings}
+}
+
+// A press held back by MinPress only goes through on a later call, so call
+// this on every poll and not only when the packet number changes.
+func (d *Debouncer) Apply(buttons WORD, now time.Time) WORD {
+	var result WORD
+	for i := range(d.buttons) {
+		b := &d.buttons[i]
+		window := time.Duration(d.Settings.Window[i] * float64(time.Second))
+		minPress := time.Duration(d.Settings.MinPress[i] * float64(time.Second))
+		raw := buttons & (1 << uint(i)) != 0
+		if raw != b.raw {
+			b.raw = raw
+			b.rawSince = now
+			if raw == b.reported {
+				// The button went back before the change was let through.
+				d.Filtered[i]++
+			}
+		}
+		inWindow := !b.changed.IsZero() && now.Sub(b.changed) < window
+		tooShort := raw && now.Sub(b.rawSince) < minPress
+		if raw != b.reported && !inWindow && !tooShort {
+			b.reported = raw
+			b.changed = now
+		}
+		if b.reported {
+			result |= 1 << uint(i)
+		}
+	}
+	return result
+}
+
+func (d *Debouncer) Reset() {
+	d.buttons = [ButtonCount]debouncedButton{}
+}
+
+// Lists the buttons that had changes thrown away, eg "Filtered bounces: A 3, X 1".
+func (d *Debouncer) String() string {
+	var counts []string
+	for i, count := range(d.Filtered) {
+		if count > 0 {
+			counts = append(counts, fmt.Sprintf("%s %d", GamepadButtonToString[1 << uint(i)], count))
+		}
+	}
+	if len(counts) == 0 {
+		return "Filtered bounces: none"
+	}
+	sort.Strings(counts)
+	return "Filtered bounces: " + strings.Join(counts, ", ")
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	DefaultDwellTime    = 1.0 // Seconds
+	DefaultDwellRadius  = 10  // Pixels
+)
+
+// Dwell click kinds, in the order the cycle button goes through them.
+const (
+	DwellLeftClick = iota
+	DwellRightClick
+	DwellDoubleClick
+	DwellDrag // The first dwell presses the left button, the next one lets go
+	dwellClickCount
+)
+
+var DwellClickToString = map[int]string {
+	DwellLeftClick   : "left click",
+	DwellRightClick  : "right click",
+	DwellDoubleClick : "double click",
+	DwellDrag        : "drag",
+}
+
+// Called whenever Progress or Click changes, so a UI can show a countdown.
+var DwellCallback = func(int, *DwellClicker) {}
+
+// Clicks for users who can't press buttons reliably. Once the cursor has
+// moved and then stays within Radius pixels for Time seconds, it clicks.
+// It then waits for the cursor to move again, so resting doesn't click over
+// and over.
+type DwellClicker struct {
+	Time         float64 // Seconds
+	Radius       float64 // Pixels
+	CycleButton  WORD // Button code that picks the next click kind, 0 for none
+	Click        int // See the constants prefixed by Dwell
+	Progress     float64 // From 0.0 when the cursor stops to 1.0 when it clicks
+	Dragging     bool // The left button is held by a drag
+
+	armed        bool
+	anchorX      LONG
+	anchorY      LONG
+	anchorTime   time.Time
+}
+
+func NewDwellClicker() DwellClicker {
+	dwell := DwellClicker{}
+	dwell.Time = DefaultDwellTime
+	dwell.Radius = DefaultDwellRadius
+	return dwell
+}
+
+// Takes the cursor position and the buttons pressed since the last update.
+// The countdown runs while nothing moves, when no new packets come in, so
+// this is called on every poll. Clicks are sent through sender.
+func (dwell *DwellClicker) Update(userIndex int, x, y LONG, pressed WORD, now time.Time, sender OutputSender) {
+	if dwell.CycleButton != 0 && pressed & dwell.CycleButton != 0 {
+		dwell.releaseDrag(sender)
+		dwell.Click = (dwell.Click + 1) % dwellClickCount
+		dwell.Progress = 0
+		dwell.armed = false
+		DwellCallback(userIndex, dwell)
+	}
+
+	distance := math.Hypot(float64(x - dwell.anchorX), float64(y - dwell.anchorY))
+	if distance > dwell.Radius {
+		dwell.anchorX, dwell.anchorY = x, y
+		dwell.anchorTime = now
+		dwell.armed = true
+		if dwell.Progress != 0 {
+			dwell.Progress = 0
+			DwellCallback(userIndex, dwell)
+		}
+		return
+	} else if !dwell.armed {
+		return
+	}
+
+	dwell.Progress = math.Min(now.Sub(dwell.anchorTime).Seconds() / dwell.Time, 1)
+	if dwell.Progress >= 1 {
+		dwell.click(sender)
+		dwell.armed = false
+	}
+	DwellCallback(userIndex, dwell)
+	if !dwell.armed {
+		dwell.Progress = 0
+	}
+}
+
+func (dwell *DwellClicker) click(sender OutputSender) {
+	left := NewMouseButtonInput(VK_LBUTTON)
+	right := NewMouseButtonInput(VK_RBUTTON)
+	switch dwell.Click {
+		case DwellLeftClick:
+			sender.Send(&left)
+			sender.Release(&left)
+		case DwellRightClick:
+			sender.Send(&right)
+			sender.Release(&right)
+		case DwellDoubleClick:
+			sender.Send(&left)
+			sender.Release(&left)
+			sender.Send(&left)
+			sender.Release(&left)
+		case DwellDrag:
+			if dwell.Dragging {
+				dwell.releaseDrag(sender)
+			} else {
+				sender.Send(&left)
+				dwell.Dragging = true
+			}
+		default:
+			panic("Internal error: unknown dwell click kind.")
+	}
+}
+
+func (dwell *DwellClicker) releaseDrag(sender OutputSender) {
+	if dwell.Dragging {
+		left := NewMouseButtonInput(VK_LBUTTON)
+		sender.Release(&left)
+		dwell.Dragging = false
+	}
+}
+
+// Lets go of a drag and stops counting down. Used when the gamepad disconnects.
+func (dwell *DwellClicker) Reset(sender OutputSender) {
+	dwell.releaseDrag(sender)
+	dwell.armed = false
+	dwell.Progress = 0
+}
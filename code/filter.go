@@ -0,0 +1,149 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Filter kinds
+const (
+	NoFilter = iota
+	Average  // Exponential moving average. Smooth but laggy.
+	OneEuro  // Smooths slow movements a lot and fast movements barely, see http://cristal.univ-lille.fr/~casiez/1euro/
+	Median   // Median of the last few samples. Removes single sample spikes.
+)
+
+type FilterSettings struct {
+	Kind              int
+	Alpha             float64 // Average: weight of the newest sample, between 0.0 and 1.0
+	MinCutoff         float64 // OneEuro: cutoff frequency in Hz when the input is still
+	Beta              float64 // OneEuro: how quickly the cutoff rises with speed
+	DerivativeCutoff  float64 // OneEuro: cutoff frequency in Hz for the speed estimate
+	Size              int     // Median: number of samples
+}
+
+// Smooths one analog channel. Timestamps are passed in rather than read from
+// the clock so the output only depends on the samples.
+type Filter struct {
+	Settings     FilterSettings
+
+	initialized  bool
+	value        float64
+	derivative   float64
+	lastTime     time.Time
+	window       []float64
+}
+
+func NewFilter(settings FilterSettings) Filter {
+	return Filter{Settings: settings}
+}
+
+func (f *Filter) Apply(value float64, now time.Time) float64 {
+	switch f.Settings.Kind {
+		case NoFilter:
+			return value
+		case Average:
+			if f.initialized {
+				value = f.Settings.Alpha * value + (1 - f.Settings.Alpha) * f.value
+			}
+		case OneEuro:
+			value = f.oneEuro(value, now)
+		case Median:
+			f.window = append(f.window, value)
+			if len(f.window) > f.Settings.Size {
+				f.window = f.window[1:]
+			}
+			sorted := append([]float64{}, f.window...)
+			sort.Float64s(sorted)
+			middle := len(sorted) / 2
+			if len(sorted) % 2 == 0 {
+				value = (sorted[middle-1] + sorted[middle]) / 2
+			} else {
+				value = sorted[middle]
+			}
+		default:
+			panic("Internal error: unknown filter kind")
+	}
+	f.initialized = true
+	f.value = value
+	f.lastTime = now
+	return value
+}
+
+func (f *Filter) oneEuro(value float64, now time.Time) float64 {
+	if !f.initialized {
+		f.derivative = 0
+		return value
+	}
+	dt := now.Sub(f.lastTime).Seconds()
+	if dt <= 0 {
+		return f.value
+	}
+	derivative := (value - f.value) / dt
+	a := smoothingFactor(dt, f.Settings.DerivativeCutoff)
+	f.derivative = a * derivative + (1 - a) * f.derivative
+	cutoff := f.Settings.MinCutoff + f.Settings.Beta * math.Abs(f.derivative)
+	a = smoothingFactor(dt, cutoff)
+	return a * value + (1 - a) * f.value
+}
+
+// The weight of the newest sample for a low-pass filter with the given cutoff in Hz.
+func smoothingFactor(dt, cutoff float64) float64 {
+	tau := 1 / (2 * math.Pi * cutoff)
+	return 1 / (1 + tau / dt)
+}
+
+func (f *Filter) Reset() {
+	*f = NewFilter(f.Settings)
+}
+
+// One filter per analog channel of a gamepad.
+type FilterBank struct {
+	LeftX         Filter
+	LeftY         Filter
+	RightX        Filter
+	RightY        Filter
+	LeftTrigger   Filter
+	RightTrigger  Filter
+}
+
+func NewFilterBank(config ProcessingConfig) FilterBank {
+	bank := FilterBank{}
+	bank.LeftX = NewFilter(config.LeftStickFilter)
+	bank.LeftY = NewFilter(config.LeftStickFilter)
+	bank.RightX = NewFilter(config.RightStickFilter)
+	bank.RightY = NewFilter(config.RightStickFilter)
+	bank.LeftTrigger = NewFilter(config.LeftTriggerFilter)
+	bank.RightTrigger = NewFilter(config.RightTriggerFilter)
+	return bank
+}
+
+// Returns a copy of the gamepad with every analog channel filtered.
+// Values are filtered as fractions of their full range so the settings don't
+// depend on whether the channel is a SHORT or a BYTE.
+func (bank *FilterBank) Apply(gamepad XInputGamepad, now time.Time) XInputGamepad {
+	const MaxStick = 32767
+	const MaxTrigger = 255
+	stick := func(f *Filter, value SHORT) SHORT {
+		filtered := f.Apply(float64(value) / MaxStick, now)
+		return SHORT(math.Max(-32768, math.Min(math.Round(filtered * MaxStick), MaxStick)))
+	}
+	trigger := func(f *Filter, value BYTE) BYTE {
+		filtered := f.Apply(float64(value) / MaxTrigger, now)
+		return BYTE(math.Max(0, math.Min(math.Round(filtered * MaxTrigger), MaxTrigger)))
+	}
+	gamepad.ThumbLX = stick(&bank.LeftX, gamepad.ThumbLX)
+	gamepad.ThumbLY = stick(&bank.LeftY, gamepad.ThumbLY)
+	gamepad.ThumbRX = stick(&bank.RightX, gamepad.ThumbRX)
+	gamepad.ThumbRY = stick(&bank.RightY, gamepad.ThumbRY)
+	gamepad.LeftTrigger = trigger(&bank.LeftTrigger, gamepad.LeftTrigger)
+	gamepad.RightTrigger = trigger(&bank.RightTrigger, gamepad.RightTrigger)
+	return gamepad
+}
+
+func (bank *FilterBank) Reset() {
+	for _, f := range([]*Filter{&bank.LeftX, &bank.LeftY, &bank.RightX, &bank.RightY, &bank.LeftTrigger, &bank.RightTrigger}) {
+		f.Reset()
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Feeds the samples to a filter bank 10 ms apart and returns what comes out.
+func applyFilterBank(config ProcessingConfig, samples []XInputGamepad) []XInputGamepad {
+	bank := NewFilterBank(config)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	out := make([]XInputGamepad, len(samples))
+	for i, sample := range(samples) {
+		out[i] = bank.Apply(sample, start.Add(time.Duration(i) * 10 * time.Millisecond))
+	}
+	return out
+}
+
+func TestFilterBankAverage(t *testing.T) {
+	config := NewProcessingConfig()
+	config.LeftStickFilter = FilterSettings{Kind: Average, Alpha: 0.5}
+	samples := []XInputGamepad{
+		{ThumbLX: 0, ThumbRX: 0},
+		{ThumbLX: 32767, ThumbRX: 32767},
+		{ThumbLX: 32767, ThumbRX: 32767},
+		{ThumbLX: -32767, ThumbRX: -32767},
+	}
+	want := []SHORT{0, 16384, 24575, -4096}
+	for i, got := range(applyFilterBank(config, samples)) {
+		if got.ThumbLX != want[i] {
+			t.Errorf("sample %d: ThumbLX = %d, want %d", i, got.ThumbLX, want[i])
+		}
+		if got.ThumbRX != samples[i].ThumbRX {
+			t.Errorf("sample %d: unfiltered ThumbRX = %d, want %d", i, got.ThumbRX, samples[i].ThumbRX)
+		}
+	}
+}
+
+func TestFilterBankOneEuro(t *testing.T) {
+	config := NewProcessingConfig()
+	config.LeftStickFilter = FilterSettings{Kind: OneEuro, MinCutoff: 1, Beta: 0, DerivativeCutoff: 1}
+	samples := []XInputGamepad{{ThumbLY: 0}}
+	for i := 0; i < 20; i++ {
+		samples = append(samples, XInputGamepad{ThumbLY: 32767})
+	}
+	out := applyFilterBank(config, samples)
+	// With a 1 Hz cutoff and 10 ms between samples the newest one weighs
+	// 1 / (1 + 100 / (2 pi)), about 0.0591.
+	if out[0].ThumbLY != 0 || out[1].ThumbLY != 1937 {
+		t.Errorf("first samples = %d %d, want 0 1937", out[0].ThumbLY, out[1].ThumbLY)
+	}
+	for i := 2; i < len(out); i++ {
+		if out[i].ThumbLY <= out[i-1].ThumbLY || out[i].ThumbLY >= 32767 {
+			t.Errorf("sample %d: ThumbLY = %d after %d, want it to rise towards 32767", i, out[i].ThumbLY, out[i-1].ThumbLY)
+		}
+	}
+
+	// A higher beta follows fast movement more closely.
+	config.LeftStickFilter.Beta = 1
+	fast := applyFilterBank(config, samples)
+	if fast[1].ThumbLY <= out[1].ThumbLY {
+		t.Errorf("beta 1 gives %d, want more than beta 0 which gives %d", fast[1].ThumbLY, out[1].ThumbLY)
+	}
+}
+
+func TestFilterBankMedian(t *testing.T) {
+	config := NewProcessingConfig()
+	config.RightTriggerFilter = FilterSettings{Kind: Median, Size: 3}
+	samples := []XInputGamepad{
+		{RightTrigger: 10},
+		{RightTrigger: 10},
+		{RightTrigger: 255}, // A single sample spike is removed
+		{RightTrigger: 10},
+		{RightTrigger: 10},
+		{RightTrigger: 200},
+		{RightTrigger: 200}, // A lasting change comes through one sample late
+	}
+	want := []BYTE{10, 10, 10, 10, 10, 10, 200}
+	for i, got := range(applyFilterBank(config, samples)) {
+		if got.RightTrigger != want[i] {
+			t.Errorf("sample %d: RightTrigger = %d, want %d", i, got.RightTrigger, want[i])
+		}
+	}
+}
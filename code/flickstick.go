@@ -0,0 +1,69 @@
+package main
+
+import (
+	"math"
+)
+
+const (
+	DefaultCountsPer360    = 12000
+	DefaultFlickTime       = 0.1 // Seconds
+	DefaultFlickThreshold  = 0.9
+)
+
+// Turns the camera of a first person game to face where the stick points.
+// Pushing the stick to the rim flicks the camera towards that direction,
+// relative to where it faces now. Rotating the stick along the rim then turns
+// the camera by the same angle. Letting go does nothing.
+// https://gyrowiki.jibbsmart.com/blog:good-gyro-controls-part-2:the-flick-stick
+type FlickStick struct {
+	IsLeft        bool
+	CountsPer360  float64 // Mouse counts the game turns a full circle for
+	FlickTime     float64 // Seconds a flick is spread over
+	Threshold     float32 // Deflection that starts a flick, between 0.0 and 1.0
+
+	flicking      bool
+	lastAngle     float64
+}
+
+func NewFlickStick(isLeft bool) FlickStick {
+	flick := FlickStick{}
+	flick.IsLeft = isLeft
+	flick.CountsPer360 = DefaultCountsPer360
+	flick.FlickTime = DefaultFlickTime
+	flick.Threshold = DefaultFlickThreshold
+	return flick
+}
+
+// Takes the normalized stick position. Returns the mouse counts to send over
+// FlickTime for a new flick and the counts to send right away for turning.
+// Positive counts turn right.
+func (flick *FlickStick) Update(x, y float32) (float64, float64) {
+	magnitude := math.Sqrt(float64(x*x + y*y))
+	if magnitude < float64(flick.Threshold) {
+		flick.flicking = false
+		return 0, 0
+	}
+	angle := math.Atan2(float64(x), float64(y)) * 180 / math.Pi // Clockwise from up
+	if !flick.flicking {
+		flick.flicking = true
+		flick.lastAngle = angle
+		return flick.counts(angle), 0
+	}
+	turn := angle - flick.lastAngle
+	// Take the short way around when crossing straight down.
+	if turn > 180 {
+		turn -= 360
+	} else if turn < -180 {
+		turn += 360
+	}
+	flick.lastAngle = angle
+	return 0, flick.counts(turn)
+}
+
+func (flick *FlickStick) counts(degrees float64) float64 {
+	return degrees / 360 * flick.CountsPer360
+}
+
+func (flick *FlickStick) Reset() {
+	flick.flicking = false
+}
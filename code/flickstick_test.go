@@ -0,0 +1,58 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// The stick position for an angle clockwise from up, at full deflection.
+func stickAt(degrees float64) (float32, float32) {
+	radians := degrees * math.Pi / 180
+	return float32(math.Sin(radians)), float32(math.Cos(radians))
+}
+
+func TestFlickStickUpdate(t *testing.T) {
+	type step struct {
+		angle      float64 // Clockwise from up, or NaN for a released stick
+		wantFlick  float64
+		wantTurn   float64
+	}
+	tests := []struct {
+		name   string
+		steps  []step
+	}{
+		{"flick right", []step{{90, 3000, 0}}},
+		{"flick left", []step{{-90, -3000, 0}}},
+		{"flick behind", []step{{180, 6000, 0}}},
+		{"hold still", []step{{45, 1500, 0}, {45, 0, 0}, {45, 0, 0}}},
+		{"turn while held", []step{{0, 0, 0}, {30, 0, 1000}, {60, 0, 1000}, {30, 0, -1000}}},
+		{"release and flick again", []step{{90, 3000, 0}, {math.NaN(), 0, 0}, {90, 3000, 0}}},
+		{"release doesn't turn", []step{{90, 3000, 0}, {math.NaN(), 0, 0}, {math.NaN(), 0, 0}}},
+		{"wrap clockwise past down", []step{{170, 5666.667, 0}, {-170, 0, 666.667}}},
+		{"wrap anticlockwise past down", []step{{-170, -5666.667, 0}, {170, 0, -666.667}}},
+	}
+	for _, test := range(tests) {
+		flick := NewFlickStick(false)
+		for i, s := range(test.steps) {
+			var x, y float32
+			if !math.IsNaN(s.angle) {
+				x, y = stickAt(s.angle)
+			}
+			gotFlick, gotTurn := flick.Update(x, y)
+			if math.Abs(gotFlick - s.wantFlick) > 0.01 || math.Abs(gotTurn - s.wantTurn) > 0.01 {
+				t.Errorf("%s, step %d: Update = %.3f %.3f, want %.3f %.3f", test.name, i, gotFlick, gotTurn, s.wantFlick, s.wantTurn)
+			}
+		}
+	}
+}
+
+func TestFlickStickThreshold(t *testing.T) {
+	flick := NewFlickStick(false)
+	flick.Threshold = 0.5
+	if gotFlick, gotTurn := flick.Update(0.4, 0); gotFlick != 0 || gotTurn != 0 {
+		t.Errorf("below the threshold: Update = %v %v, want 0 0", gotFlick, gotTurn)
+	}
+	if gotFlick, _ := flick.Update(0.6, 0); math.Abs(gotFlick - 3000) > 0.01 {
+		t.Errorf("past the threshold: flick = %v, want 3000", gotFlick)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	DefaultGestureWindow     = 0.5 // Seconds
+	DefaultGestureTolerance  = 30  // Degrees
+	GestureRim               = 0.7 // Deflection that counts as moving along the rim
+	GestureCentre            = 0.3 // Deflection that counts as centred
+	FlickGestureTime         = 0.1 // Seconds a flick may take from the centre to the rim
+)
+
+// Gesture kinds. Forward is right, as in fighting games played on the left side.
+const (
+	QuarterCircleForward = iota // Down, down forward, forward
+	QuarterCircleBack
+	HalfCircleForward           // Back, down, forward
+	HalfCircleBack
+	CircleClockwise             // A full turn starting anywhere
+	CircleCounterClockwise
+	FlickUp                     // From the centre to the rim in a hurry
+	FlickDown
+	FlickLeft
+	FlickRight
+)
+
+var StringToGesture = map[string]int {
+	"QCF"                     : QuarterCircleForward,
+	"QUARTERCIRCLEFORWARD"    : QuarterCircleForward,
+	"QCB"                     : QuarterCircleBack,
+	"QUARTERCIRCLEBACK"       : QuarterCircleBack,
+	"HCF"                     : HalfCircleForward,
+	"HALFCIRCLEFORWARD"       : HalfCircleForward,
+	"HCB"                     : HalfCircleBack,
+	"HALFCIRCLEBACK"          : HalfCircleBack,
+	"CIRCLECW"                : CircleClockwise,
+	"CIRCLECLOCKWISE"         : CircleClockwise,
+	"CIRCLECCW"               : CircleCounterClockwise,
+	"CIRCLECOUNTERCLOCKWISE"  : CircleCounterClockwise,
+	"FLICKUP"                 : FlickUp,
+	"FLICKDOWN"               : FlickDown,
+	"FLICKLEFT"               : FlickLeft,
+	"FLICKRIGHT"              : FlickRight,
+}
+
+// Angles are in degrees, counter-clockwise from right.
+type gestureShape struct {
+	flick     bool
+	start     float64 // Where a turn starts, or the direction of a flick
+	sweep     float64 // How far a turn goes, negative is clockwise
+	anyStart  bool
+}
+
+var gestureShapes = map[int]gestureShape {
+	QuarterCircleForward   : {start: -90, sweep: 90},
+	QuarterCircleBack      : {start: -90, sweep: -90},
+	HalfCircleForward      : {start: 180, sweep: 180},
+	HalfCircleBack         : {start: 0, sweep: -180},
+	CircleClockwise        : {sweep: -360, anyStart: true},
+	CircleCounterClockwise : {sweep: 360, anyStart: true},
+	FlickUp                : {flick: true, start: 90},
+	FlickDown              : {flick: true, start: -90},
+	FlickLeft              : {flick: true, start: 180},
+	FlickRight             : {flick: true, start: 0},
+}
+
+// Taps an output when a thumbstick draws a gesture. The recent stick positions
+// are kept for Window seconds and checked against the gesture on every update.
+type StickGesture struct {
+	IsLeft     bool
+	Kind       int
+	Output     *MouseOrKeyboardInput
+	Window     float64 // Seconds the whole gesture must fit in
+	Tolerance  float64 // Degrees a direction may be off by
+
+	history    []gestureSample
+}
+
+type gestureSample struct {
+	time       time.Time
+	magnitude  float64
+	angle      float64
+}
+
+func NewStickGesture(isLeft bool, kind int, output *MouseOrKeyboardInput) StickGesture {
+	g := StickGesture{}
+	g.IsLeft = isLeft
+	g.Kind = kind
+	g.Output = output
+	g.Window = DefaultGestureWindow
+	g.Tolerance = DefaultGestureTolerance
+	return g
+}
+
+// Takes the normalized stick position. Returns true once when the gesture is done.
+func (g *StickGesture) Update(x, y float32, now time.Time) bool {
+	sample := gestureSample{now, math.Sqrt(float64(x*x + y*y)), math.Atan2(float64(y), float64(x)) * 180 / math.Pi}
+	g.history = append(g.history, sample)
+	oldest := now.Add(-time.Duration(g.Window * float64(time.Second)))
+	for len(g.history) > 0 && g.history[0].time.Before(oldest) {
+		g.history = g.history[1:]
+	}
+	if sample.magnitude < GestureRim {
+		return false
+	}
+
+	shape := gestureShapes[g.Kind]
+	var done bool
+	if shape.flick {
+		done = g.isFlick(shape)
+	} else {
+		done = g.isTurn(shape)
+	}
+	if done {
+		// Start over so the same motion doesn't fire twice.
+		g.history = g.history[:0]
+	}
+	return done
+}
+
+// The stick points the right way now and was centred a moment ago.
+func (g *StickGesture) isFlick(shape gestureShape) bool {
+	latest := g.history[len(g.history)-1]
+	if angleBetween(latest.angle, shape.start) > g.Tolerance {
+		return false
+	}
+	for i := len(g.history) - 2; i >= 0; i-- {
+		sample := g.history[i]
+		if latest.time.Sub(sample.time).Seconds() > FlickGestureTime {
+			break
+		} else if sample.magnitude < GestureCentre {
+			return true
+		}
+	}
+	return false
+}
+
+// Walks back from the latest position along the rim, adding up how far the
+// stick turned, until it reaches a position the turn could have started from.
+func (g *StickGesture) isTurn(shape gestureShape) bool {
+	latest := g.history[len(g.history)-1]
+	if !shape.anyStart && angleBetween(latest.angle, shape.start + shape.sweep) > g.Tolerance {
+		return false
+	}
+	turned := 0.0
+	for i := len(g.history) - 1; i > 0; i-- {
+		previous := g.history[i-1]
+		if previous.magnitude < GestureRim {
+			break
+		}
+		turned += wrapAngle(g.history[i].angle - previous.angle)
+		// Only turns in the gesture's direction count.
+		if turned * shape.sweep < 0 || math.Abs(turned) < math.Abs(shape.sweep) - g.Tolerance {
+			continue
+		}
+		if shape.anyStart || angleBetween(previous.angle, shape.start) <= g.Tolerance {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *StickGesture) Reset() {
+	g.history = g.history[:0]
+}
+
+// Brings an angle in degrees between -180 and 180.
+func wrapAngle(angle float64) float64 {
+	angle = math.Mod(angle + 180, 360)
+	if angle < 0 {
+		angle += 360
+	}
+	return angle - 180
+}
+
+// Degrees between two directions, between 0 and 180.
+func angleBetween(a, b float64) float64 {
+	return math.Abs(wrapAngle(a - b))
+}
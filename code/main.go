@@ -5,27 +5,53 @@ import (
 	"fmt"
 	"io/ioutil"
 	"runtime"
+	"os"
+	"strconv"
 )
 
 // @TODO Add support for hotloading.
 
 func main() {
+	var err error
 	if runtime.GOOS != "windows" {
 		fmt.Println("Yaypad is only supported on Windows. Exiting.")
 		return
 	}
+	if len(os.Args) > 1 && os.Args[1] == "calibrate" {
+		userIndex := 0
+		if len(os.Args) > 2 {
+			userIndex, err = strconv.Atoi(os.Args[2])
+			panicIfNotNil(err)
+		}
+		panicIfNotNil(RunCalibration(userIndex))
+		return
+	}
+	debug := len(os.Args) > 1 && os.Args[1] == "debug"
     // @TODO Take the path as a command-line argument!
     path := "bindings.yay"
 	bytes, err := ioutil.ReadFile(path)
 	panicIfNotNil(err)
     binds, err := ParseBindings(string(bytes))
 	panicIfNotNil(err)
+	controller := NewController(0, &binds)
+	var copilot Copilot
+	if binds.CopilotSlot >= 0 {
+		copilot = NewCopilot(0, binds.CopilotSlot, binds.CopilotPriority, &controller.Config)
+		copilot.PilotCalibration, err = LoadCalibration(0)
+		panicIfNotNil(err)
+		copilot.CopilotCalibration, err = LoadCalibration(binds.CopilotSlot)
+		panicIfNotNil(err)
+	} else {
+		controller.Config.Calibration, err = LoadCalibration(0)
+		panicIfNotNil(err)
+	}
 
 	GamepadConnectedCallback = func(userIndex int) {
 		fmt.Println("gamepad connected")
 	}
     GamepadDisconnectedCallback = func(userIndex int) {
 		fmt.Println("gamepad disconnected")
+		controller.Disconnect()
 	}
 	// @TODO Using the arrow keys right now works as inteded in VSCode,
 	// but test the behaviour in games. Is the input spammed or fired only once?
@@ -36,14 +62,21 @@ func main() {
 			}
 		}
 	} */
-    GamepadInputCallback = func(userIndex int, state XInputState) {
-		for in, out := range(binds.Bindings) {
-			if state.InputValueBool(*in) {
-				out.Send()
-			}
+    GamepadPollCallback = func(userIndex int, state XInputState) {
+		controller.Poll(state, time.Now())
+	}
+	if debug {
+		GamepadInputCallback = func(userIndex int, state XInputState) {
+			fmt.Println(NewGamepadState(state, &controller.Config))
+			fmt.Println(controller.Debouncer.String())
 		}
 	}
-	go PollGamepad(0)
+	go controller.Output.Run()
+	if binds.CopilotSlot >= 0 {
+		go copilot.Run()
+	} else {
+		go PollGamepad(0)
+	}
 
 	for {
 		time.Sleep(time.Second) // Leaving this out causes the program to freeze after some time.
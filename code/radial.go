@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math"
+)
+
+// Called whenever a radial menu opens, closes or changes its selection.
+// Intended for drawing the menu on screen.
+var RadialMenuCallback = func(int, *RadialMenu) {}
+
+// Holding the button opens the menu and the thumbstick angle selects one of
+// the slices. Releasing the button fires the selected slice.
+// Slice 0 is centred on straight up and the rest follow clockwise.
+type RadialMenu struct {
+	Button    WORD
+	IsLeft    bool
+	Slices    []*MouseOrKeyboardInput
+
+	Open      bool
+	Selected  int // -1 if nothing is selected
+
+	deflected bool
+}
+
+func NewRadialMenu(button WORD, isLeft bool, slices []*MouseOrKeyboardInput) RadialMenu {
+	menu := RadialMenu{}
+	menu.Button = button
+	menu.IsLeft = isLeft
+	menu.Slices = slices
+	menu.Selected = -1
+	return menu
+}
+
+// The selected slice is sent through sender when it fires.
+func (menu *RadialMenu) Update(userIndex int, state GamepadState, sender OutputSender) {
+	if !state.IsButtonDown(menu.Button) {
+		if menu.Open {
+			menu.Open = false
+			if menu.Selected != -1 {
+				out := menu.Slices[menu.Selected]
+				sender.Send(out)
+				sender.Release(out)
+			}
+			menu.Selected = -1
+			menu.deflected = false
+			RadialMenuCallback(userIndex, menu)
+		}
+		return
+	}
+
+	changed := !menu.Open
+	menu.Open = true
+	var x, y, magnitude float32
+	if menu.IsLeft {
+		x, y = state.LeftThumbstick()
+		magnitude = state.LeftThumbstickMagnitude()
+	} else {
+		x, y = state.RightThumbstick()
+		magnitude = state.RightThumbstickMagnitude()
+	}
+	// Letting the stick go back to the centre keeps the last selection.
+	stick := NewGamepadThumbstickInput(menu.IsLeft, true)
+	menu.deflected = magnitude > 0 && state.Config.IsPressed(stick, magnitude, menu.deflected)
+	if menu.deflected {
+		slice := radialSlice(x, y, len(menu.Slices))
+		if slice != menu.Selected {
+			menu.Selected = slice
+			changed = true
+		}
+	}
+	if changed {
+		RadialMenuCallback(userIndex, menu)
+	}
+}
+
+// Closes the menu without firing anything. Used when the gamepad disconnects.
+func (menu *RadialMenu) Close(userIndex int) {
+	if menu.Open {
+		menu.Open = false
+		menu.Selected = -1
+		menu.deflected = false
+		RadialMenuCallback(userIndex, menu)
+	}
+}
+
+// Returns the slice the vector points at. Slice 0 is centred on straight up.
+func radialSlice(x, y float32, sliceCount int) int {
+	angle := math.Atan2(float64(x), float64(y)) // Clockwise from up, -pi to pi
+	sliceWidth := 2 * math.Pi / float64(sliceCount)
+	slice := int(math.Floor((angle + sliceWidth / 2) / sliceWidth))
+	if slice < 0 {
+		slice += sliceCount
+	}
+	return slice % sliceCount
+}
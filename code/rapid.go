@@ -0,0 +1,48 @@
+package main
+
+// Actuates a trigger by its motion instead of its position, like the rapid
+// trigger of Hall effect keyboards. Pulling the trigger in by Distance presses
+// it and letting it out by Distance from the deepest point releases it, wherever
+// that happens. Works on the raw trigger values, after filtering, so the
+// threshold and trigger transform don't get in the way. A fully released
+// trigger is always released.
+type RapidTrigger struct {
+	Distance  BYTE // Raw trigger units, 1 to 255
+
+	pressed   bool
+	extreme   BYTE // Deepest point while pressed, shallowest while released
+}
+
+func NewRapidTrigger(distance BYTE) RapidTrigger {
+	rapid := RapidTrigger{}
+	rapid.Distance = distance
+	return rapid
+}
+
+// Takes the raw trigger value and returns whether the trigger is pressed.
+func (rapid *RapidTrigger) Update(value BYTE) bool {
+	if value == 0 {
+		rapid.pressed = false
+		rapid.extreme = 0
+	} else if rapid.pressed {
+		if value > rapid.extreme {
+			rapid.extreme = value
+		} else if rapid.extreme - value >= rapid.Distance {
+			rapid.pressed = false
+			rapid.extreme = value
+		}
+	} else {
+		if value < rapid.extreme {
+			rapid.extreme = value
+		} else if value - rapid.extreme >= rapid.Distance {
+			rapid.pressed = true
+			rapid.extreme = value
+		}
+	}
+	return rapid.pressed
+}
+
+func (rapid *RapidTrigger) Reset() {
+	rapid.pressed = false
+	rapid.extreme = 0
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+)
+
+// Moves the trigger from point to point one unit at a time. Every point is
+// included once.
+func triggerSweep(points ...int) []BYTE {
+	values := []BYTE{BYTE(points[0])}
+	for i := 1; i < len(points); i++ {
+		step := 1
+		if points[i] < points[i-1] {
+			step = -1
+		}
+		for v := points[i-1] + step; v != points[i] + step; v += step {
+			values = append(values, BYTE(v))
+		}
+	}
+	return values
+}
+
+// Sweeps a trigger with a distance of 10 and checks the values where it
+// changes between pressed and released.
+func TestRapidTriggerSweep(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []BYTE
+		changes  map[int]bool // Index into values to whether it is pressed from there on
+	}{
+		{"pull in", triggerSweep(0, 255), map[int]bool{10: true}},
+		{"pull in and let out", triggerSweep(0, 200, 150),
+			map[int]bool{10: true, 201 + 9: false}},
+		{"let out and pull in again", triggerSweep(0, 200, 150, 180),
+			map[int]bool{10: true, 201 + 9: false, 201 + 50 + 9: true}},
+		{"let out from the deepest point", triggerSweep(0, 100, 95, 120, 105),
+			map[int]bool{10: true, 131 + 9: false}},
+		{"wiggle then pull in from the shallowest point", triggerSweep(0, 50, 30, 38, 35, 45),
+			map[int]bool{10: true, 51 + 9: false, 82 + 4: true}},
+		{"full release", append(triggerSweep(0, 50, 45), triggerSweep(0, 9)...),
+			map[int]bool{10: true, 51 + 5: false}},
+	}
+	for _, test := range(tests) {
+		rapid := NewRapidTrigger(10)
+		want := false
+		for i, value := range(test.values) {
+			if change, ok := test.changes[i]; ok {
+				want = change
+			}
+			if got := rapid.Update(value); got != want {
+				t.Errorf("%s: value %d at %d: Update = %v, want %v", test.name, value, i, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestGamepadTriggerInputSide(t *testing.T) {
+	config := NewProcessingConfig()
+	state := NewGamepadState(XInputState{Gamepad: XInputGamepad{LeftTrigger: 255}}, &config)
+	if state.InputValueFloat(NewGamepadTriggerInput(true)) != 1 {
+		t.Errorf("the left trigger input doesn't read the left trigger")
+	}
+	if state.InputValueFloat(NewGamepadTriggerInput(false)) != 0 {
+		t.Errorf("the right trigger input reads the left trigger")
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMouseSpeed   = 1000 // Pixels per second
+	DefaultScrollSpeed  = 10   // Notches per second
+	DefaultRepeatDelay  = 0.5  // Seconds
+	DefaultRepeatRate   = 20   // Repeats per second
+	DefaultOutputRate   = 250  // Ticks per second
+)
+
+// Emits continuous outputs at a fixed rate: mouse movement, scrolling, key
+// repeat, turbo and pulsing. Amounts are worked out from the real time between ticks,
+// so speeds don't depend on how often the gamepad reports.
+// Controller.Update sets what should happen, Run makes it happen.
+type OutputScheduler struct {
+	Interval     time.Duration
+	RepeatDelay  time.Duration // 0 turns key repeat off
+	RepeatRate   float64 // Repeats per second
+
+	sender       OutputSender
+	moveMouse    func(dx, dy LONG)
+	mutex        sync.Mutex
+	lastTick     time.Time
+	velocityX    float64 // Pixels per second
+	velocityY    float64
+	scrollSpeed  float64 // Notches per second, positive is up
+	remainderX   float64 // Fractions of a pixel or notch not sent yet
+	remainderY   float64
+	remainderScroll  float64
+	bursts       []burst
+	movers       []Mover
+	held         map[*MouseOrKeyboardInput]*heldOutput
+}
+
+// Where the scheduler sends the keys and mouse buttons it holds, see outputRouter.
+type OutputSender interface {
+	Send(out *MouseOrKeyboardInput)
+	Release(out *MouseOrKeyboardInput)
+}
+
+// Something that moves the mouse with a velocity that changes over time on its
+// own, like a coasting trackball. Step is called every tick with the seconds
+// since the last one and returns the velocity in pixels per second.
+type Mover interface {
+	Step(dt float64) (float64, float64)
+}
+
+// Mouse movement spread evenly over some time.
+type burst struct {
+	x, y          float64 // Pixels to send in all
+	duration      time.Duration
+	elapsed       time.Duration
+	sentX, sentY  float64 // Whole pixels sent so far
+}
+
+// A key or mouse button held by the scheduler.
+type heldOutput struct {
+	turbo  float64 // Presses per second, 0 if not turbo
+	pulse  float64 // Seconds per pulse, 0 if not pulsing
+	duty   float64 // Fraction of each pulse to hold the output for
+	start  time.Time // When pulsing started
+	down   bool
+	next   time.Time // When to repeat or toggle turbo next
+}
+
+func NewOutputScheduler(bindings *Bindings, sender OutputSender) *OutputScheduler {
+	s := &OutputScheduler{}
+	s.sender = sender
+	s.moveMouse = sendMoveMouseInput
+	s.Interval = time.Duration(float64(time.Second) / bindings.OutputRate)
+	s.RepeatDelay = time.Duration(bindings.RepeatDelay * float64(time.Second))
+	s.RepeatRate = bindings.RepeatRate
+	s.held = map[*MouseOrKeyboardInput]*heldOutput{}
+	return s
+}
+
+// Intended usage: call this in a goroutine.
+func (s *OutputScheduler) Run() {
+	ticker := time.NewTicker(s.Interval)
+	for now := range(ticker.C) {
+		s.Tick(now)
+	}
+}
+
+// Sets the speed of the mouse in pixels per second and of the scroll wheel in
+// notches per second. Stays in effect until called again.
+func (s *OutputScheduler) SetMotion(velocityX, velocityY, scrollSpeed float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.velocityX = velocityX
+	s.velocityY = velocityY
+	s.scrollSpeed = scrollSpeed
+}
+
+// Must be called before Run.
+func (s *OutputScheduler) AddMover(mover Mover) {
+	s.movers = append(s.movers, mover)
+}
+
+// Moves the mouse by dx and dy pixels on the next tick, on top of any motion.
+func (s *OutputScheduler) AddMotion(dx, dy float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.remainderX += dx
+	s.remainderY += dy
+}
+
+// Moves the mouse by dx and dy pixels spread evenly over duration seconds.
+func (s *OutputScheduler) AddBurst(dx, dy, duration float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if duration <= 0 {
+		s.remainderX += dx
+		s.remainderY += dy
+		return
+	}
+	s.bursts = append(s.bursts, burst{x: dx, y: dy, duration: time.Duration(math.Round(duration * float64(time.Second)))})
+}
+
+// Holds a key or mouse button down until Release is called. Keys repeat
+// like a keyboard would. With turbo set the output is pressed and released
+// that many times per second instead.
+func (s *OutputScheduler) Press(out *MouseOrKeyboardInput, turbo float64, now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, found := s.held[out]; found {
+		return
+	}
+	held := &heldOutput{turbo: turbo, down: true}
+	if turbo > 0 {
+		held.next = now.Add(time.Duration(float64(time.Second) / turbo / 2))
+	} else {
+		held.next = now.Add(s.RepeatDelay)
+	}
+	s.held[out] = held
+	s.sender.Send(out)
+}
+
+// Presses the output once every period seconds and holds it for duty of each
+// period, duty being between 0.0 and 1.0. Call again whenever the duty changes.
+// A duty of 0 lets go.
+func (s *OutputScheduler) Pulse(out *MouseOrKeyboardInput, duty, period float64, now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	held, found := s.held[out]
+	if duty <= 0 {
+		if found && held.down {
+			s.sender.Release(out)
+		}
+		delete(s.held, out)
+		return
+	}
+	if !found {
+		held = &heldOutput{pulse: period, start: now, down: true}
+		s.held[out] = held
+		s.sender.Send(out)
+	}
+	held.duty = math.Min(duty, 1)
+}
+
+func (s *OutputScheduler) Release(out *MouseOrKeyboardInput) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	held, found := s.held[out]
+	if !found {
+		return
+	}
+	if held.down {
+		s.sender.Release(out)
+	}
+	delete(s.held, out)
+}
+
+// Lets go of everything and stops all motion. Used when the gamepad disconnects.
+func (s *OutputScheduler) ReleaseAll() {
+	s.SetMotion(0, 0, 0)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.bursts = nil
+	s.remainderX, s.remainderY, s.remainderScroll = 0, 0, 0
+	for out, held := range(s.held) {
+		if held.down {
+			s.sender.Release(out)
+		}
+		delete(s.held, out)
+	}
+}
+
+func (s *OutputScheduler) Tick(now time.Time) {
+	// Anything longer than this was the process being suspended, not motion.
+	const MaxStep = 100 * time.Millisecond
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	step := now.Sub(s.lastTick)
+	if s.lastTick.IsZero() || step > MaxStep {
+		step = s.Interval
+	}
+	s.lastTick = now
+	dt := step.Seconds()
+
+	s.remainderX += s.velocityX * dt
+	s.remainderY += s.velocityY * dt
+	for _, mover := range(s.movers) {
+		vx, vy := mover.Step(dt)
+		s.remainderX += vx * dt
+		s.remainderY += vy * dt
+	}
+	bursts := s.bursts[:0]
+	for _, b := range(s.bursts) {
+		// Whole pixels are sent until the last tick so rounding errors
+		// can't build up, and the last tick sends exactly what is left.
+		b.elapsed += step
+		x, y := b.x, b.y
+		if b.elapsed < b.duration {
+			progress := b.elapsed.Seconds() / b.duration.Seconds()
+			x = math.Trunc(b.x * progress)
+			y = math.Trunc(b.y * progress)
+		}
+		s.remainderX += x - b.sentX
+		s.remainderY += y - b.sentY
+		b.sentX, b.sentY = x, y
+		if b.elapsed < b.duration {
+			bursts = append(bursts, b)
+		}
+	}
+	s.bursts = bursts
+	dx := math.Trunc(s.remainderX)
+	dy := math.Trunc(s.remainderY)
+	s.remainderX -= dx
+	s.remainderY -= dy
+	if dx != 0 || dy != 0 {
+		s.moveMouse(LONG(dx), LONG(dy))
+	}
+
+	s.remainderScroll += s.scrollSpeed * dt
+	notches := math.Trunc(s.remainderScroll)
+	s.remainderScroll -= notches
+	if notches != 0 {
+		sendScrollInput(DWORD(LONG(notches) * WHEEL_DELTA))
+	}
+
+	for out, held := range(s.held) {
+		if held.pulse > 0 {
+			elapsed := now.Sub(held.start).Seconds()
+			down := math.Mod(elapsed, held.pulse) < held.duty * held.pulse
+			if down && !held.down {
+				s.sender.Send(out)
+			} else if !down && held.down {
+				s.sender.Release(out)
+			}
+			held.down = down
+			continue
+		}
+		if now.Before(held.next) {
+			continue
+		}
+		if held.turbo > 0 {
+			if held.down {
+				s.sender.Release(out)
+			} else {
+				s.sender.Send(out)
+			}
+			held.down = !held.down
+			held.next = held.next.Add(time.Duration(float64(time.Second) / held.turbo / 2))
+		} else if out.IsKeyboard && s.RepeatDelay > 0 && s.RepeatRate > 0 {
+			s.sender.Send(out)
+			held.next = held.next.Add(time.Duration(float64(time.Second) / s.RepeatRate))
+		} else {
+			held.next = now.Add(time.Hour)
+		}
+		if held.next.Before(now) {
+			// Don't try to catch up after a hiccup.
+			held.next = now
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Ticks a scheduler every interval for the given time and returns the mouse
+// moves it sends, one per tick with 0 for none.
+func tickBurst(dx, duration float64, interval time.Duration, total time.Duration) []LONG {
+	bindings := NewBindings()
+	s := NewOutputScheduler(&bindings, nil)
+	s.Interval = interval
+	moves := []LONG{}
+	moved := LONG(0)
+	s.moveMouse = func(dx, dy LONG) {
+		moved += dx
+	}
+	s.AddBurst(dx, 0, duration)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for now := start; now.Sub(start) < total; now = now.Add(interval) {
+		moved = 0
+		s.Tick(now)
+		moves = append(moves, moved)
+	}
+	return moves
+}
+
+// A flick is sent as a burst spread over FlickTime.
+func TestOutputSchedulerBurst(t *testing.T) {
+	tests := []struct {
+		name      string
+		duration  float64 // Seconds
+		interval  time.Duration
+		ticks     int // Ticks the burst should take
+	}{
+		{"even ticks", 0.1, 4 * time.Millisecond, 25},
+		{"uneven ticks", 0.1, 7 * time.Millisecond, 15},
+		{"long ticks", 0.1, 75 * time.Millisecond, 2},
+		{"no duration", 0, 4 * time.Millisecond, 1},
+		{"negative duration", -1, 4 * time.Millisecond, 1},
+	}
+	for _, test := range(tests) {
+		moves := tickBurst(3000, test.duration, test.interval, 500 * time.Millisecond)
+		sum := LONG(0)
+		for i, move := range(moves) {
+			sum += move
+			if i >= test.ticks && move != 0 {
+				t.Errorf("%s: tick %d moved %d after the burst should have ended", test.name, i, move)
+			}
+		}
+		if sum != 3000 {
+			t.Errorf("%s: moves add up to %d, want 3000", test.name, sum)
+		}
+		if test.ticks > 1 && (moves[0] == 0 || moves[test.ticks-1] == 0) {
+			t.Errorf("%s: moves = %v, want them spread over %d ticks", test.name, moves[:test.ticks], test.ticks)
+		}
+	}
+}
@@ -50,15 +50,22 @@ type MouseOrKeyboardInput struct {
 	IsMouseButton  bool
 	IsScroll       bool
 	IsMouseMove    bool
+	IsWarp         bool
+
+	// Mouse movements that follow the sign of the gamepad input, eg MOUSEX.
+	IsAxis         bool
 
 	// If it's a keyboard input, set this to a value prefixed by VK_.
-	// If it's a mouse button input, set this to a value prefixed by MOUSEEVENTF_.
-	// If it's a scroll input, set this to the amount of scroll.
+	// If it's a mouse button input, set this to a value prefixed by VK_ as well.
+	// If it's a scroll input, set this to the amount of scroll. See NewScrollInput.
 	Value  DWORD
 
 	// Only used for mouse movements.
 	X  LONG
 	Y  LONG
+
+	// Only used for cursor warps.
+	Warp  *Warp
 }
 
 func NewKeyboardInput(key DWORD) MouseOrKeyboardInput {
@@ -75,10 +82,12 @@ func NewMouseButtonInput(button DWORD) MouseOrKeyboardInput {
 	return in
 }
 
-func NewScrollInput(amount DWORD) MouseOrKeyboardInput {
+// Negative amounts scroll down. They are stored as two's complement, which is
+// what MOUSEINPUT.mouseData expects.
+func NewScrollInput(amount LONG) MouseOrKeyboardInput {
 	in := MouseOrKeyboardInput{}
 	in.IsScroll = true
-	in.Value = amount
+	in.Value = DWORD(amount)
 	return in
 }
 
@@ -90,30 +99,98 @@ func NewMouseMoveInput(dx, dy LONG) MouseOrKeyboardInput {
 	return in
 }
 
+func NewMouseAxisInput(dx, dy LONG) MouseOrKeyboardInput {
+	in := NewMouseMoveInput(dx, dy)
+	in.IsAxis = true
+	return in
+}
+
+func NewWarpInput(points []WarpPoint) MouseOrKeyboardInput {
+	in := MouseOrKeyboardInput{}
+	in.IsWarp = true
+	in.Warp = &Warp{Points: points}
+	return in
+}
+
 func (input MouseOrKeyboardInput) Send() {
 	if input.IsKeyboard {
 		sendKeyDownInput(WORD(input.Value))
 	} else if input.IsMouseButton {
-		sendMouseButtonInput(input.Value)
+		sendMouseButtonInput(int(input.Value), true)
 	} else if input.IsScroll {
 		sendScrollInput(input.Value)
 	} else if input.IsMouseMove {
 		sendMoveMouseInput(input.X, input.Y)
+	} else if input.IsWarp {
+		input.Warp.Fire()
+	}
+}
+
+// Returns true for inputs that should fire once per press rather than for
+// as long as the gamepad input is held.
+func (input MouseOrKeyboardInput) FiresOnce() bool {
+	return input.IsWarp
+}
+
+// Undoes Send for inputs that stay held, ie keys and mouse buttons. Does nothing otherwise.
+func (input MouseOrKeyboardInput) Release() {
+	if input.IsKeyboard {
+		sendKeyUpInput(WORD(input.Value))
+	} else if input.IsMouseButton {
+		sendMouseButtonInput(int(input.Value), false)
 	}
 }
 
 func sendKeyDownInput(key WORD) {
+	sendKeyboardInput(key, true)
+}
+
+func sendKeyUpInput(key WORD) {
+	sendKeyboardInput(key, false)
+}
+
+func sendKeyboardInput(key WORD, down bool) {
 	var kb KeyboardInput
 	kb.InputType = INPUT_KEYBOARD
 	kb.Keyboard.VirtualKeyCode = key
+	if !down {
+		kb.Keyboard.Flags |= KEYEVENTF_KEYUP
+	}
 	callSendInput(unsafe.Pointer(&kb), unsafe.Sizeof(kb))
 }
 
-func sendMouseButtonInput(button DWORD) {
+// button is a value prefixed by VK_.
+func sendMouseButtonInput(button int, down bool) {
 	var m MouseInput
 	m.InputType = INPUT_MOUSE
-	m.Mouse.Flags |= button
-	callSendInput(unsafe.Pointer(&m), unsafe.Sizeof(m))
+	downFlag, upFlag, data := mouseButtonFlags(button)
+	m.Mouse.Data = data
+	if down {
+		m.Mouse.Flags = downFlag
+	} else {
+		m.Mouse.Flags = upFlag
+	}
+	if m.Mouse.Flags != 0 {
+		callSendInput(unsafe.Pointer(&m), unsafe.Sizeof(m))
+	}
+}
+
+// Returns the MOUSEEVENTF_ flags for pressing and releasing the button and
+// the mouse data that goes with them. Unknown buttons get zero flags.
+func mouseButtonFlags(button int) (DWORD, DWORD, DWORD) {
+	switch button {
+		case VK_LBUTTON:
+			return MOUSEEVENTF_LEFTDOWN, MOUSEEVENTF_LEFTUP, 0
+		case VK_RBUTTON:
+			return MOUSEEVENTF_RIGHTDOWN, MOUSEEVENTF_RIGHTUP, 0
+		case VK_MBUTTON:
+			return MOUSEEVENTF_MIDDLEDOWN, MOUSEEVENTF_MIDDLEUP, 0
+		case VK_XBUTTON1:
+			return MOUSEEVENTF_XDOWN, MOUSEEVENTF_XUP, XBUTTON1
+		case VK_XBUTTON2:
+			return MOUSEEVENTF_XDOWN, MOUSEEVENTF_XUP, XBUTTON2
+	}
+	return 0, 0, 0
 }
 
 func sendScrollInput(amount DWORD) {
@@ -133,6 +210,32 @@ func sendMoveMouseInput(dx LONG, dy LONG) {
 	callSendInput(unsafe.Pointer(&m), unsafe.Sizeof(m))
 }
 
+// x and y are between 0 and 65535, covering the primary screen.
+func sendAbsoluteMoveInput(x LONG, y LONG) {
+	var m MouseInput
+	m.InputType = INPUT_MOUSE
+	m.Mouse.X = x
+	m.Mouse.Y = y
+	m.Mouse.Flags |= MOUSEEVENTF_MOVE | MOUSEEVENTF_ABSOLUTE
+	callSendInput(unsafe.Pointer(&m), unsafe.Sizeof(m))
+}
+
+// x and y are between 0 and 65535, covering every monitor.
+func sendVirtualDeskMoveInput(x LONG, y LONG) {
+	var m MouseInput
+	m.InputType = INPUT_MOUSE
+	m.Mouse.X = x
+	m.Mouse.Y = y
+	m.Mouse.Flags |= MOUSEEVENTF_MOVE | MOUSEEVENTF_ABSOLUTE | MOUSEEVENTF_VIRTUALDESK
+	callSendInput(unsafe.Pointer(&m), unsafe.Sizeof(m))
+}
+
+// Presses and releases a mouse button. button is a value prefixed by VK_.
+func sendMouseClickInput(button int) {
+	sendMouseButtonInput(button, true)
+	sendMouseButtonInput(button, false)
+}
+
 func callSendInput(inputStructure unsafe.Pointer, sizeOfStructure uintptr) {
 	var numberOfInputs = 1
 	// https://docs.microsoft.com/en-us/windows/win32/api/winuser/nf-winuser-sendinput
@@ -162,6 +265,8 @@ const (
 	MOUSEEVENTF_WHEEL      = 0x0800
 	MOUSEEVENTF_XDOWN      = 0x0080
 	MOUSEEVENTF_XUP        = 0x0100
+	MOUSEEVENTF_VIRTUALDESK = 0x4000
+	MOUSEEVENTF_ABSOLUTE   = 0x8000
 
 	// For use in TagMouseInput.Data
 	WHEEL_DELTA = 120
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"time"
+)
+
+const DefaultSequenceGap = 0.5 // Seconds
+
+// Taps an output when buttons are pressed in order, like a cheat code.
+// Pressing any other button or waiting longer than Gap between two steps
+// starts over. The buttons keep working as usual unless Swallow is set, in
+// which case every step after the first is hidden from the other bindings
+// until it is released. The first step can't be hidden since nobody knows
+// yet that a sequence has started.
+type ButtonSequence struct {
+	Steps      []WORD // Button codes. See the constants prefixed by XINPUT_GAMEPAD_
+	Output     *MouseOrKeyboardInput
+	Gap        float64 // Seconds, 0 uses SEQUENCE_GAP
+	Swallow    bool
+
+	presses    []sequencePress // The latest presses, as many as there are steps
+	swallowed  WORD
+}
+
+type sequencePress struct {
+	button  WORD
+	time    time.Time
+}
+
+func NewButtonSequence(steps []WORD, output *MouseOrKeyboardInput) ButtonSequence {
+	seq := ButtonSequence{}
+	seq.Steps = steps
+	seq.Output = output
+	return seq
+}
+
+// Takes the buttons pressed since the last update and the buttons held now.
+// Returns true when the last step is done.
+func (seq *ButtonSequence) Update(pressed, held WORD, now time.Time) bool {
+	seq.swallowed &= held
+	done := false
+	for i := 0; i < ButtonCount; i++ {
+		button := WORD(1 << uint(i))
+		if pressed & button == 0 {
+			continue
+		}
+		seq.presses = append(seq.presses, sequencePress{button, now})
+		if len(seq.presses) > len(seq.Steps) {
+			seq.presses = seq.presses[1:]
+		}
+		progress := seq.progress()
+		if progress > 1 && seq.Swallow {
+			seq.swallowed |= button
+		}
+		if progress == len(seq.Steps) {
+			seq.presses = seq.presses[:0]
+			done = true
+		}
+	}
+	return done
+}
+
+// Returns how many steps the latest presses complete, which is the longest
+// run of latest presses that matches the start of the sequence.
+// Pressing A A B for A B still counts the last two.
+func (seq *ButtonSequence) progress() int {
+	gap := time.Duration(seq.Gap * float64(time.Second))
+	for count := len(seq.presses); count > 0; count-- {
+		presses := seq.presses[len(seq.presses) - count:]
+		matches := true
+		for i, press := range(presses) {
+			if press.button != seq.Steps[i] || i > 0 && press.time.Sub(presses[i-1].time) > gap {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return count
+		}
+	}
+	return 0
+}
+
+// Buttons to hide from the other bindings.
+func (seq *ButtonSequence) Swallowed() WORD {
+	return seq.swallowed
+}
+
+func (seq *ButtonSequence) Reset() {
+	seq.presses = seq.presses[:0]
+	seq.swallowed = 0
+}
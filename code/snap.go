@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+)
+
+const DefaultSnapHysteresis = 5 // Degrees
+
+// Locks a stick driven cursor to horizontal or vertical motion when the stick
+// points close enough to an axis, so small diagonal drift doesn't move it off
+// a line. Once locked, the stick has to leave the window by Hysteresis degrees
+// more before the lock lets go, so it doesn't flicker at the edge.
+type AngleSnap struct {
+	Window      float64 // Degrees either side of an axis, 0 turns snapping off
+	Hysteresis  float64 // Degrees
+}
+
+func NewAngleSnap() AngleSnap {
+	snap := AngleSnap{}
+	snap.Hysteresis = DefaultSnapHysteresis
+	return snap
+}
+
+type snapAxis int
+
+const (
+	notSnapped snapAxis = iota
+	snappedX
+	snappedY
+)
+
+// Remembers which axis a stick is locked to between updates.
+type angleSnapper struct {
+	AngleSnap
+	locked  snapAxis
+}
+
+// Takes the normalized stick position. A locked position keeps its distance
+// from the centre, up to 1.0, and loses its other axis.
+func (snap *angleSnapper) Apply(x, y float32) (float32, float32) {
+	magnitude := float32(math.Sqrt(float64(x*x + y*y)))
+	if snap.Window <= 0 || magnitude == 0 {
+		snap.locked = notSnapped
+		return x, y
+	}
+	// Degrees away from the X axis, between 0 and 90 whichever way the stick points.
+	angle := math.Atan2(math.Abs(float64(y)), math.Abs(float64(x))) * 180 / math.Pi
+	release := snap.Window + snap.Hysteresis
+	switch snap.locked {
+		case snappedX:
+			if angle > release {
+				snap.locked = notSnapped
+			}
+		case snappedY:
+			if 90 - angle > release {
+				snap.locked = notSnapped
+			}
+	}
+	if snap.locked == notSnapped {
+		if angle <= snap.Window {
+			snap.locked = snappedX
+		} else if 90 - angle <= snap.Window {
+			snap.locked = snappedY
+		}
+	}
+
+	magnitude = float32(math.Min(float64(magnitude), 1))
+	switch snap.locked {
+		case snappedX:
+			if x < 0 {
+				return -magnitude, 0
+			}
+			return magnitude, 0
+		case snappedY:
+			if y < 0 {
+				return 0, -magnitude
+			}
+			return 0, magnitude
+	}
+	return x, y
+}
+
+func (snap *angleSnapper) Reset() {
+	snap.locked = notSnapped
+}
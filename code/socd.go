@@ -0,0 +1,189 @@
+package main
+
+import (
+	"sync"
+)
+
+// Decides what happens when opposite directions are held at the same time
+// (simultaneous opposing cardinal directions, SOCD).
+type SOCDMode int
+
+const (
+	NoSOCD          SOCDMode = iota // Both directions go through
+	SOCDNeutral                     // Neither direction goes through
+	SOCDLastWins                    // The direction pressed last goes through
+	SOCDFirstWins                   // The direction pressed first goes through
+	SOCDUpPriority                  // Up beats down, left and right cancel out
+)
+
+var StringToSOCDMode = map[string]SOCDMode {
+	"NONE"       : NoSOCD,
+	"NEUTRAL"    : SOCDNeutral,
+	"LASTWINS"   : SOCDLastWins,
+	"LAST"       : SOCDLastWins,
+	"FIRSTWINS"  : SOCDFirstWins,
+	"FIRST"      : SOCDFirstWins,
+	"UPPRIORITY" : SOCDUpPriority,
+}
+
+// Remembers the order in which the two directions of an axis were pressed.
+type socdAxis struct {
+	negative, positive  bool
+	last                int // -1 for negative, 1 for positive, 0 if pressed together
+	first               int
+}
+
+// Takes which directions are held and returns which should go through.
+// favoured is the direction that wins under SOCDUpPriority, 0 for neither.
+func (axis *socdAxis) resolve(negative, positive bool, mode SOCDMode, favoured int) (bool, bool) {
+	newNegative := negative && !axis.negative
+	newPositive := positive && !axis.positive
+	if newNegative && newPositive {
+		axis.last = 0
+	} else if newNegative {
+		axis.last = -1
+	} else if newPositive {
+		axis.last = 1
+	}
+	if negative && !positive {
+		axis.first = -1
+	} else if positive && !negative {
+		axis.first = 1
+	} else if !negative && !positive || newNegative && newPositive {
+		axis.first = 0
+	}
+	axis.negative, axis.positive = negative, positive
+
+	if !negative || !positive || mode == NoSOCD {
+		return negative, positive
+	}
+	winner := 0
+	switch mode {
+		case SOCDLastWins:
+			winner = axis.last
+		case SOCDFirstWins:
+			winner = axis.first
+		case SOCDUpPriority:
+			winner = favoured
+	}
+	return winner < 0, winner > 0
+}
+
+// Cleans the D-pad bits of XInputGamepad.Buttons before the bindings see them.
+type SOCDCleaner struct {
+	Mode        SOCDMode
+
+	horizontal  socdAxis
+	vertical    socdAxis
+}
+
+func NewSOCDCleaner(mode SOCDMode) SOCDCleaner {
+	cleaner := SOCDCleaner{}
+	cleaner.Mode = mode
+	return cleaner
+}
+
+func (cleaner *SOCDCleaner) Buttons(buttons WORD) WORD {
+	if cleaner.Mode == NoSOCD {
+		return buttons
+	}
+	left, right := cleaner.horizontal.resolve(
+		buttons & XINPUT_GAMEPAD_DPAD_LEFT != 0, buttons & XINPUT_GAMEPAD_DPAD_RIGHT != 0, cleaner.Mode, 0)
+	down, up := cleaner.vertical.resolve(
+		buttons & XINPUT_GAMEPAD_DPAD_DOWN != 0, buttons & XINPUT_GAMEPAD_DPAD_UP != 0, cleaner.Mode, 1)
+	buttons &^= XINPUT_GAMEPAD_DPAD_LEFT | XINPUT_GAMEPAD_DPAD_RIGHT | XINPUT_GAMEPAD_DPAD_DOWN | XINPUT_GAMEPAD_DPAD_UP
+	if left {
+		buttons |= XINPUT_GAMEPAD_DPAD_LEFT
+	}
+	if right {
+		buttons |= XINPUT_GAMEPAD_DPAD_RIGHT
+	}
+	if down {
+		buttons |= XINPUT_GAMEPAD_DPAD_DOWN
+	}
+	if up {
+		buttons |= XINPUT_GAMEPAD_DPAD_UP
+	}
+	return buttons
+}
+
+func (cleaner *SOCDCleaner) Reset() {
+	cleaner.horizontal = socdAxis{}
+	cleaner.vertical = socdAxis{}
+}
+
+// Cleans pairs of opposite direction keys, such as the keys of a StickBinding.
+// Each controller has its own, so one controller's keys never cancel out
+// another's. The controller's keys go through it, see outputRouter.
+type KeySOCD struct {
+	Mode   SOCDMode
+
+	mutex  sync.Mutex
+	pairs  map[WORD]*keyPair
+}
+
+type keyPair struct {
+	keys       [2]WORD // Negative and positive direction
+	favoured   int
+	axis       socdAxis
+	requested  [2]bool // What the bindings asked for
+	sent       [2]bool // What was actually sent
+}
+
+func NewKeySOCD(mode SOCDMode) *KeySOCD {
+	k := &KeySOCD{}
+	k.Mode = mode
+	k.pairs = map[WORD]*keyPair{}
+	return k
+}
+
+// Registers two opposite keys. Under SOCDUpPriority the positive one wins
+// if favourPositive is set, otherwise they cancel out.
+func (k *KeySOCD) AddPair(negative, positive WORD, favourPositive bool) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if _, found := k.pairs[negative]; found {
+		return
+	} else if _, found := k.pairs[positive]; found {
+		return
+	}
+	pair := &keyPair{keys: [2]WORD{negative, positive}}
+	if favourPositive {
+		pair.favoured = 1
+	}
+	k.pairs[negative] = pair
+	k.pairs[positive] = pair
+}
+
+// Returns false if the key isn't a direction key, in which case the caller
+// sends it as usual. Otherwise sends whatever the key and its opposite should
+// do now.
+func (k *KeySOCD) Filter(key WORD, down bool) bool {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	pair, found := k.pairs[key]
+	if !found || k.Mode == NoSOCD {
+		return false
+	}
+	side := 0
+	if key == pair.keys[1] {
+		side = 1
+	}
+	if down && pair.requested[side] {
+		// Key repeat only goes through if the key is actually down.
+		if pair.sent[side] {
+			sendKeyboardInput(key, true)
+		}
+		return true
+	}
+	pair.requested[side] = down
+	negative, positive := pair.axis.resolve(pair.requested[0], pair.requested[1], k.Mode, pair.favoured)
+	want := [2]bool{negative, positive}
+	for i := range(want) {
+		if want[i] != pair.sent[i] {
+			sendKeyboardInput(pair.keys[i], want[i])
+			pair.sent[i] = want[i]
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"math"
+)
+
+const DefaultOuterRing = 0.8
+
+// A component must be at least this fraction of the magnitude for its key to
+// be held. sin(22.5 degrees) splits the stick into eight equal directions.
+var StickDiagonalFactor = float32(math.Sin(math.Pi / 8))
+
+// Binds a whole thumbstick to four direction keys, e.g. WASD.
+// Past the outer ring the modifier is held as well, which is how keyboard
+// games usually tell walking and running apart.
+type StickBinding struct {
+	IsLeft       bool
+	Keys         [4]WORD // Up, left, down, right
+	Modifier     WORD
+	HasModifier  bool
+	OuterRing    float32 // Between 0.0 and 1.0
+
+	held          [4]bool
+	modifierHeld  bool
+}
+
+func NewStickBinding(isLeft bool, up, left, down, right WORD) StickBinding {
+	b := StickBinding{}
+	b.IsLeft = isLeft
+	b.Keys = [4]WORD{up, left, down, right}
+	b.OuterRing = DefaultOuterRing
+	return b
+}
+
+// Presses and releases keys so that they match the stick's position.
+// sendKey presses the key if down is set and lets go of it otherwise.
+func (b *StickBinding) Update(state GamepadState, sendKey func(key WORD, down bool)) {
+	var x, y, magnitude float32
+	if b.IsLeft {
+		x, y = state.LeftThumbstick()
+		magnitude = state.LeftThumbstickMagnitude()
+	} else {
+		x, y = state.RightThumbstick()
+		magnitude = state.RightThumbstickMagnitude()
+	}
+
+	var want [4]bool
+	if magnitude > 0 {
+		// Components are scaled by magnitude, so compare against it rather than 1.0.
+		edge := magnitude * StickDiagonalFactor
+		for i, component := range([4]float32{y, -x, -y, x}) {
+			axis := NewGamepadThumbstickInput(b.IsLeft, i % 2 == 1)
+			want[i] = component > edge && state.Config.IsPressed(axis, component, b.held[i])
+		}
+	}
+	ring := state.Config.Hysteresis.At(float64(b.OuterRing))
+	wantModifier := b.HasModifier && ring.IsPressed(magnitude, b.modifierHeld)
+
+	// Press the modifier before the direction keys so the game never sees a
+	// single frame of walking when the stick is flicked straight to the rim.
+	if wantModifier && !b.modifierHeld {
+		sendKey(b.Modifier, true)
+		b.modifierHeld = true
+	}
+	for i, key := range(b.Keys) {
+		if want[i] && !b.held[i] {
+			sendKey(key, true)
+		} else if !want[i] && b.held[i] {
+			sendKey(key, false)
+		}
+		b.held[i] = want[i]
+	}
+	if !wantModifier && b.modifierHeld {
+		sendKey(b.Modifier, false)
+		b.modifierHeld = false
+	}
+}
+
+// Lets go of every key held by this binding. Used when the gamepad disconnects.
+func (b *StickBinding) Release(sendKey func(key WORD, down bool)) {
+	for i, key := range(b.Keys) {
+		if b.held[i] {
+			sendKey(key, false)
+			b.held[i] = false
+		}
+	}
+	if b.modifierHeld {
+		sendKey(b.Modifier, false)
+		b.modifierHeld = false
+	}
+}
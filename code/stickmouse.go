@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math"
+	"sync"
+)
+
+const (
+	DefaultTrackballFriction  = 4 // Per second
+	DefaultRingAcceleration   = 1 // Extra speed per second, 1 being MOUSE_SPEED
+	DefaultRingMaxSpeed       = 4 // Times MOUSE_SPEED
+	RingThreshold             = 0.95 // Deflection that counts as holding the stick at the rim
+	releaseWindow             = 0.1 // Seconds looked back to find the speed to coast at
+	stopSpeed                 = 1 // Pixels per second below which coasting stops
+)
+
+// Moves the cursor with a whole thumbstick. Holding the stick at the rim
+// slowly speeds the cursor up. As a trackball, letting go of the stick after
+// a fast motion leaves the cursor coasting until friction stops it.
+type StickMouse struct {
+	IsLeft            bool
+	Trackball         bool
+	Speed             float64 // Pixels per second at full deflection
+	Friction          float64 // The coasting speed shrinks by a factor of e every 1/Friction seconds
+	RingAcceleration  float64 // 0 turns ring acceleration off
+	RingMaxSpeed      float64
+
+	mutex             sync.Mutex
+	x, y              float64
+	vx, vy            float64
+	boost             float64 // Extra speed from ring acceleration, 0 is none
+	clock             float64 // Seconds stepped so far
+	history           []mouseSample
+	coasting          bool
+}
+
+type mouseSample struct {
+	time    float64
+	vx, vy  float64
+}
+
+func NewStickMouse(isLeft bool, trackball bool) *StickMouse {
+	mouse := &StickMouse{}
+	mouse.IsLeft = isLeft
+	mouse.Trackball = trackball
+	mouse.Speed = DefaultMouseSpeed
+	mouse.Friction = DefaultTrackballFriction
+	mouse.RingAcceleration = DefaultRingAcceleration
+	mouse.RingMaxSpeed = DefaultRingMaxSpeed
+	return mouse
+}
+
+// Takes the normalized, curved stick position.
+func (mouse *StickMouse) SetStick(x, y float32) {
+	mouse.mutex.Lock()
+	defer mouse.mutex.Unlock()
+	mouse.x = float64(x)
+	mouse.y = -float64(y) // The stick's Y axis points up while the screen's points down.
+}
+
+// Called by OutputScheduler every tick. Returns the velocity in pixels per second.
+func (mouse *StickMouse) Step(dt float64) (float64, float64) {
+	mouse.mutex.Lock()
+	defer mouse.mutex.Unlock()
+	mouse.clock += dt
+	magnitude := math.Sqrt(mouse.x*mouse.x + mouse.y*mouse.y)
+
+	if magnitude == 0 {
+		mouse.boost = 0
+		if !mouse.Trackball {
+			mouse.vx, mouse.vy = 0, 0
+		} else if !mouse.coasting {
+			mouse.coasting = true
+			mouse.vx, mouse.vy = mouse.releaseVelocity()
+		} else {
+			decay := math.Exp(-mouse.Friction * dt)
+			mouse.vx *= decay
+			mouse.vy *= decay
+			if math.Sqrt(mouse.vx*mouse.vx + mouse.vy*mouse.vy) < stopSpeed {
+				mouse.vx, mouse.vy = 0, 0
+			}
+		}
+		mouse.history = mouse.history[:0]
+		return mouse.vx, mouse.vy
+	}
+
+	mouse.coasting = false
+	if magnitude >= RingThreshold && mouse.RingAcceleration > 0 {
+		mouse.boost = math.Min(mouse.boost + mouse.RingAcceleration * dt, mouse.RingMaxSpeed - 1)
+	} else {
+		mouse.boost = 0
+	}
+	speed := mouse.Speed * (1 + mouse.boost)
+	mouse.vx, mouse.vy = mouse.x * speed, mouse.y * speed
+
+	mouse.history = append(mouse.history, mouseSample{mouse.clock, mouse.vx, mouse.vy})
+	for len(mouse.history) > 0 && mouse.history[0].time < mouse.clock - releaseWindow {
+		mouse.history = mouse.history[1:]
+	}
+	return mouse.vx, mouse.vy
+}
+
+// A stick springs back to the centre when let go, slowing down on the way.
+// The fastest recent velocity is what the user meant to throw the cursor with.
+func (mouse *StickMouse) releaseVelocity() (float64, float64) {
+	var vx, vy, fastest float64
+	for _, sample := range(mouse.history) {
+		speed := sample.vx*sample.vx + sample.vy*sample.vy
+		if speed > fastest {
+			vx, vy, fastest = sample.vx, sample.vy, speed
+		}
+	}
+	return vx, vy
+}
+
+// Stops the cursor. Used when the gamepad disconnects.
+func (mouse *StickMouse) Reset() {
+	mouse.mutex.Lock()
+	defer mouse.mutex.Unlock()
+	mouse.x, mouse.y, mouse.vx, mouse.vy, mouse.boost = 0, 0, 0, 0, 0
+	mouse.history = mouse.history[:0]
+	mouse.coasting = false
+}
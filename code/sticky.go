@@ -0,0 +1,97 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+const DefaultStickyDoublePress = 0.4 // Seconds
+
+// Sticky modifier states
+const (
+	stickyOff = iota
+	stickyLatched // Held until the next other key or mouse button is let go
+	stickyLocked  // Held until the modifier is pressed again
+)
+
+// Sticky keys for modifiers bound to gamepad buttons, so chords like
+// CTRL+SHIFT+T can be pressed one button at a time. Pressing a modifier
+// latches it for the next other output, pressing it twice within DoublePress
+// seconds locks it and pressing it once more lets go.
+// Each controller has its own, so one controller's outputs don't let go of
+// another's modifiers. The controller's outputs go through outputRouter,
+// which calls OutputDone.
+type StickyModifiers struct {
+	Enabled      bool
+	DoublePress  float64 // Seconds
+
+	mutex        sync.Mutex
+	states       map[WORD]int
+	lastPress    map[WORD]time.Time
+}
+
+func NewStickyModifiers() *StickyModifiers {
+	sticky := &StickyModifiers{}
+	sticky.DoublePress = DefaultStickyDoublePress
+	sticky.states = map[WORD]int{}
+	sticky.lastPress = map[WORD]time.Time{}
+	return sticky
+}
+
+func IsModifierKey(key WORD) bool {
+	switch key {
+		case VK_SHIFT, VK_LSHIFT, VK_RSHIFT, VK_CONTROL, VK_LCONTROL, VK_RCONTROL,
+			VK_MENU, VK_LMENU, VK_RMENU, VK_LWIN, VK_RWIN:
+			return true
+	}
+	return false
+}
+
+// Called instead of pressing the modifier when a binding's button goes down.
+// Releasing the button does nothing.
+func (sticky *StickyModifiers) Press(key WORD, now time.Time) {
+	sticky.mutex.Lock()
+	defer sticky.mutex.Unlock()
+	doublePress := time.Duration(sticky.DoublePress * float64(time.Second))
+	switch sticky.states[key] {
+		case stickyOff:
+			sendKeyboardInput(key, true)
+			sticky.states[key] = stickyLatched
+		case stickyLatched:
+			if now.Sub(sticky.lastPress[key]) <= doublePress {
+				sticky.states[key] = stickyLocked
+			} else {
+				sendKeyboardInput(key, false)
+				sticky.states[key] = stickyOff
+			}
+		case stickyLocked:
+			sendKeyboardInput(key, false)
+			sticky.states[key] = stickyOff
+	}
+	sticky.lastPress[key] = now
+}
+
+// Lets go of the latched modifiers. Called when any other key or mouse
+// button is let go.
+func (sticky *StickyModifiers) OutputDone() {
+	sticky.mutex.Lock()
+	defer sticky.mutex.Unlock()
+	for key, state := range(sticky.states) {
+		if state == stickyLatched {
+			sendKeyboardInput(key, false)
+			sticky.states[key] = stickyOff
+		}
+	}
+}
+
+// Lets go of every modifier, locked or not. Used when the gamepad disconnects.
+func (sticky *StickyModifiers) ReleaseAll() {
+	sticky.mutex.Lock()
+	defer sticky.mutex.Unlock()
+	for key, state := range(sticky.states) {
+		if state != stickyOff {
+			sendKeyboardInput(key, false)
+		}
+		delete(sticky.states, key)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"math"
+)
+
+// Reshapes a thumbstick's position. The steps always run in this order:
+//  1. The calibration, see Calibration
+//  2. Rotate, counter-clockwise in degrees
+//  3. Swap the axes
+//  4. Invert X and Y
+//  5. The dead zone, see DeadZone
+//  6. Scale X and Y
+//  7. The response curve, see ResponseCurve
+// Steps 2 to 4 fix up adapters and rotated sticks, so they come before the
+// dead zone. Scaling is a sensitivity setting and comes after it.
+type StickTransform struct {
+	Rotation  float64
+	Swap      bool
+	InvertX   bool
+	InvertY   bool
+	ScaleX    float64
+	ScaleY    float64
+}
+
+func NewStickTransform() StickTransform {
+	t := StickTransform{}
+	t.ScaleX = 1
+	t.ScaleY = 1
+	return t
+}
+
+// Runs the steps that come before the dead zone.
+func (t StickTransform) Before(x, y float64) (float64, float64) {
+	if t.Rotation != 0 {
+		radians := t.Rotation * math.Pi / 180
+		sin, cos := math.Sin(radians), math.Cos(radians)
+		x, y = x*cos - y*sin, x*sin + y*cos
+	}
+	if t.Swap {
+		x, y = y, x
+	}
+	if t.InvertX {
+		x = -x
+	}
+	if t.InvertY {
+		y = -y
+	}
+	return x, y
+}
+
+// Runs the steps that come after the dead zone. The result is clamped to
+// -1.0 and 1.0 per axis.
+func (t StickTransform) After(x, y float64) (float64, float64) {
+	return math.Max(-1, math.Min(x * t.ScaleX, 1)), math.Max(-1, math.Min(y * t.ScaleY, 1))
+}
+
+// Reshapes a trigger's value after calibration and before the threshold is
+// applied. The range is stretched to cover 0.0 to 1.0 first, then the value is inverted.
+type TriggerTransform struct {
+	Invert  bool
+	Min     float64 // Values below this read as 0.0
+	Max     float64 // Values above this read as 1.0
+}
+
+func NewTriggerTransform() TriggerTransform {
+	t := TriggerTransform{}
+	t.Max = 1
+	return t
+}
+
+// value is between 0.0 and 1.0 and so is the return value.
+func (t TriggerTransform) Apply(value float64) float64 {
+	value = rescale(value, t.Min, t.Max)
+	if t.Invert {
+		value = 1 - value
+	}
+	return value
+}
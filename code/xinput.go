@@ -21,9 +21,6 @@ var GamepadInputCallback         = func(int, XInputState) {}
 var ConnectedPollTime    = time.Millisecond
 var DisconnectedPollTime = time.Second
 
-// These must be between 0.0 and 1.0
-var ThumbstickDeadZone = DefaultThumbstickDeadZone
-var TriggerThreshold   = DefaultTriggerThreshold
 
 type XInputState struct {
 	PacketNumber  DWORD
@@ -44,25 +41,31 @@ func (state XInputState) IsButtonDown(button WORD) bool {
 	return state.Gamepad.Buttons & button != 0
 }
 
+// A raw state together with the settings used to normalize it.
+type GamepadState struct {
+	XInputState
+	Config  *ProcessingConfig
+}
+
+func NewGamepadState(state XInputState, config *ProcessingConfig) GamepadState {
+	return GamepadState{state, config}
+}
+
 // Return value is between 0.0 and 1.0
-func (state XInputState) LeftTrigger() float32 {
-	return trigger(state.Gamepad.LeftTrigger)
+func (state GamepadState) LeftTrigger() float32 {
+	return trigger(state.Config.Calibration.LeftTrigger.Trigger(state.Gamepad.LeftTrigger), state.Config.TriggerThreshold, state.Config.LeftTriggerTransform)
 }
 
 // Return value is between 0.0 and 1.0
-func (state XInputState) RightTrigger() float32 {
-	return trigger(state.Gamepad.RightTrigger)
+func (state GamepadState) RightTrigger() float32 {
+	return trigger(state.Config.Calibration.RightTrigger.Trigger(state.Gamepad.RightTrigger), state.Config.TriggerThreshold, state.Config.RightTriggerTransform)
 }
 
-func trigger(triggerValue BYTE) float32 {
-	const MaxMagnitude = 255 // Max value of a BYTE ie max value of a trigger
-	threshMagnitude := TriggerThreshold * MaxMagnitude
-	if float64(triggerValue) > threshMagnitude {
-		if triggerValue > MaxMagnitude {
-			// Due to imperfect hardware this value can be exceeded
-			triggerValue = MaxMagnitude
-		}
-		normTriggerValue := (float64(triggerValue) - threshMagnitude) / (MaxMagnitude - threshMagnitude)
+// triggerValue is the calibrated value and threshold must be between 0.0 and 1.0
+func trigger(triggerValue float64, threshold float64, transform TriggerTransform) float32 {
+	value := transform.Apply(triggerValue)
+	if value > threshold {
+		normTriggerValue := (value - threshold) / (1 - threshold)
 		if normTriggerValue > 1 {
 			normTriggerValue = 1
 		}
@@ -71,59 +74,85 @@ func trigger(triggerValue BYTE) float32 {
 	return 0
 }
 
-// Return value is between 0.0 and 1.0
-func (state XInputState) LeftThumbstickX() float32 {
-	return state.thumbstick(state.Gamepad.ThumbLX, state.Gamepad.ThumbLY, true)
+// Return value is between -1.0 and 1.0
+func (state GamepadState) LeftThumbstickX() float32 {
+	x, _ := state.LeftThumbstick()
+	return x
 }
 
-// Return value is between 0.0 and 1.0
-func (state XInputState) LeftThumbstickY() float32 {
-	return state.thumbstick(state.Gamepad.ThumbLX, state.Gamepad.ThumbLY, false)
+// Return value is between -1.0 and 1.0
+func (state GamepadState) LeftThumbstickY() float32 {
+	_, y := state.LeftThumbstick()
+	return y
 }
 
-// Return value is between 0.0 and 1.0
-func (state XInputState) RightThumbstickX() float32 {
-	return state.thumbstick(state.Gamepad.ThumbRX, state.Gamepad.ThumbRY, true)
+// Return value is between -1.0 and 1.0
+func (state GamepadState) RightThumbstickX() float32 {
+	x, _ := state.RightThumbstick()
+	return x
 }
 
-// Return value is between 0.0 and 1.0
-func (state XInputState) RightThumbstickY() float32 {
-	return state.thumbstick(state.Gamepad.ThumbRX, state.Gamepad.ThumbRY, false)
-}
-
-func (state XInputState) thumbstick(thumbstickX SHORT, thumbstickY SHORT, xAxis bool) float32 {
-	const MaxMagnitude = 32767 // Max value of a SHORT ie max value of a thumbstick
-	zoneMagnitude := ThumbstickDeadZone * MaxMagnitude	
-
-	// I used this method to implement dead zones and normalization.
-	// https://docs.microsoft.com/en-us/windows/win32/xinput/getting-started-with-xinput#dead-zone
-	x := float64(thumbstickX)
-	y := float64(thumbstickY)
-	magnitude := math.Sqrt(x*x + y*y)
-	if magnitude > zoneMagnitude {
-		if magnitude > MaxMagnitude {
-			// Due to imperfect hardware this value can be exceeded
-			magnitude = MaxMagnitude
-		}
-		// This is just a shortened version of what the article does
-		// in the link above.
-		var numerator float64
-		if xAxis {
-			numerator = x * (magnitude - zoneMagnitude)
-		} else {
-			numerator = y * (magnitude - zoneMagnitude)
-		}
-		denominator := magnitude * (MaxMagnitude - zoneMagnitude)
-		// Normalized to 0.0 to 1.0
-		normLx := float32(numerator / denominator)
-		if normLx > 1 {
-			normLx = 1
-		} else if normLx < -1 {
-			normLx = -1
-		}
-		return normLx
+// Return value is between -1.0 and 1.0
+func (state GamepadState) RightThumbstickY() float32 {
+	_, y := state.RightThumbstick()
+	return y
+}
+
+// Returns both normalized axes of the left thumbstick.
+func (state GamepadState) LeftThumbstick() (float32, float32) {
+	x, y, _ := state.leftThumbstickAll()
+	return x, y
+}
+
+// Returns both normalized axes of the right thumbstick.
+func (state GamepadState) RightThumbstick() (float32, float32) {
+	x, y, _ := state.rightThumbstickAll()
+	return x, y
+}
+
+// Return value is between 0.0 and 1.0, 0.0 being inside the dead zone.
+func (state GamepadState) LeftThumbstickMagnitude() float32 {
+	_, _, magnitude := state.leftThumbstickAll()
+	return magnitude
+}
+
+// Return value is between 0.0 and 1.0, 0.0 being inside the dead zone.
+func (state GamepadState) RightThumbstickMagnitude() float32 {
+	_, _, magnitude := state.rightThumbstickAll()
+	return magnitude
+}
+
+func (state GamepadState) leftThumbstickAll() (float32, float32, float32) {
+	calibration := state.Config.Calibration
+	x := calibration.LeftX.Stick(state.Gamepad.ThumbLX)
+	y := calibration.LeftY.Stick(state.Gamepad.ThumbLY)
+	return thumbstick(x, y, state.Config.LeftDeadZone, state.Config.LeftStickTransform)
+}
+
+func (state GamepadState) rightThumbstickAll() (float32, float32, float32) {
+	calibration := state.Config.Calibration
+	x := calibration.RightX.Stick(state.Gamepad.ThumbRX)
+	y := calibration.RightY.Stick(state.Gamepad.ThumbRY)
+	return thumbstick(x, y, state.Config.RightDeadZone, state.Config.RightStickTransform)
+}
+
+// Takes the calibrated x and y values. Returns the normalized x and y values
+// and the normalized magnitude. See StickTransform for the order things are done in.
+func thumbstick(thumbstickX float64, thumbstickY float64, zone DeadZone, transform StickTransform) (float32, float32, float32) {
+	x, y := transform.Before(thumbstickX, thumbstickY)
+	x, y, _ = zone.Apply(x, y)
+	x, y = transform.After(x, y)
+	magnitude := math.Min(math.Sqrt(x*x + y*y), 1)
+	return clampAxis(float32(x)), clampAxis(float32(y)), float32(magnitude)
+}
+
+func clampAxis(value float32) float32 {
+	if value > 1 {
+		return 1
+	} else if value < -1 {
+		return -1
 	}
-	return 0
+	return value
 }
 
 type GamepadInput struct {
@@ -135,6 +164,12 @@ type GamepadInput struct {
     Button        WORD // Button code. See the constants prefixed by XINPUT_GAMEPAD_
 	IsLeft        bool // Determines which trigger or thumbstick is used.
 	IsX           bool // Determines the thumbstick axis.
+
+	Curve         *ResponseCurve // Overrides the thumbstick's curve if set.
+	Turbo         float64 // Presses per second while held, 0 for no turbo.
+	Pulse         float64 // Seconds per press when pulsing by deflection, 0 for no pulsing.
+	Hysteresis    *Hysteresis // Overrides the global press and release thresholds if set.
+	Rapid         *RapidTrigger // Replaces the thresholds of a trigger if set.
 }
 
 func NewGamepadButtonInput(button WORD) GamepadInput {
@@ -147,7 +182,7 @@ func NewGamepadButtonInput(button WORD) GamepadInput {
 func NewGamepadTriggerInput(isLeft bool) GamepadInput {
 	input := GamepadInput{}
 	input.IsTrigger = true
-	input.IsLeft = true
+	input.IsLeft = isLeft
 	return input
 }
 
@@ -165,7 +200,7 @@ func NewGamepadThumbstickInput(isLeft bool, isX bool) GamepadInput {
  * if input.IsTrigger    returns [0.0, 1.0]
  * if input.IsThumbstick returns [-1.0, 1.0]
  */
-func (state XInputState) InputValueFloat(input GamepadInput) float32 {
+func (state GamepadState) InputValueFloat(input GamepadInput) float32 {
     if input.IsButton {
         if state.IsButtonDown(input.Button) {
             return 1;
@@ -198,7 +233,7 @@ func (state XInputState) InputValueFloat(input GamepadInput) float32 {
 }
 
 // Returns true if the button is down or the trigger/thumbstick is non-zero.
-func (state XInputState) InputValueBool(input GamepadInput) bool {
+func (state GamepadState) InputValueBool(input GamepadInput) bool {
 	val := state.InputValueFloat(input);
 	if val == 0 {
 		return false;
@@ -240,9 +275,9 @@ func PollGamepad(userIndex int) {
 	}
 }
 
-func (state XInputState) String() string {
+func (state GamepadState) String() string {
 	var s strings.Builder
-	s.WriteString("XInputState {\n")
+	s.WriteString("GamepadState {\n")
 	s.WriteString(fmt.Sprintf("\tPacketNumber: %d\n", state.PacketNumber))
 	s.WriteString("\tButtons: ")
 	for key, value := range GamepadButtonToString {
@@ -335,6 +370,23 @@ var StringToGamepadButton = map[string]int {
 // Initialized in init()
 var GamepadButtonToString map[int]string
 
+// Maps to true for the left thumbstick.
+var StringToThumbstick = map[string]bool {
+	"LTHUMB"          : true,
+	"LSTICK"          : true,
+	"LEFTTHUMB"       : true,
+	"LEFTSTICK"       : true,
+	"LTHUMBSTICK"     : true,
+	"LEFTTHUMBSTICK"  : true,
+
+	"RTHUMB"          : false,
+	"RSTICK"          : false,
+	"RIGHTTHUMB"      : false,
+	"RIGHTSTICK"      : false,
+	"RTHUMBSTICK"     : false,
+	"RIGHTTHUMBSTICK" : false,
+}
+
 const (
 	DefaultThumbstickDeadZone = 0.25
 	DefaultTriggerThreshold   = 0.1
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Reads .yay files. A file is a list of statements, one per line, each being a
+// left hand side, an equals sign and a right hand side:
+//   LEFT_STICK_CURVE = POWER 2 # Comments start with a hash
+// Words are matched without regard to case or underscores, see Token.Normalized.
+// Strings in double quotes keep their case and underscores and may contain
+// \" and \\. The parser only knows the shape of a statement; what the words
+// mean is up to ParseBindings and ParseCalibration, which report errors at
+// the token they are about with Token.Errorf.
+
+type TokenKind int
+
+const (
+	WordToken TokenKind = iota
+	StringToken
+	EqualsToken
+	PlusToken
+	CommaToken
+)
+
+// Lines and columns start at 1. Columns count characters, not bytes.
+type Position struct {
+	Line    int
+	Column  int
+}
+
+type Token struct {
+	Kind  TokenKind
+	Text  string // As written, without the quotes for strings
+	Pos   Position
+}
+
+// Returns the word in upper case with underscores removed, so DPAD_UP,
+// dpad_up and DPADUP are the same. Strings and symbols are left alone.
+func (t Token) Normalized() string {
+	if t.Kind != WordToken {
+		return t.Text
+	}
+	return strings.Replace(strings.ToUpper(t.Text), "_", "", -1)
+}
+
+// Returns the normalized word, or "" if the token isn't a word, so that
+// "SPACE" in quotes never matches the key SPACE.
+func (t Token) Word() string {
+	if t.Kind != WordToken {
+		return ""
+	}
+	return t.Normalized()
+}
+
+// Returns an error pointing at the token.
+func (t Token) Errorf(format string, args ...interface{}) error {
+	return &YayError{t.Pos, fmt.Sprintf(format, args...)}
+}
+
+type Statement struct {
+	Lhs     []Token
+	Equals  Token
+	Rhs     []Token
+}
+
+type YayFile struct {
+	Statements  []Statement
+}
+
+// An error pointing at a place in a .yay file.
+type YayError struct {
+	Pos      Position
+	Message  string
+}
+
+func (e *YayError) Error() string {
+	return fmt.Sprintf("Error on line %d, column %d: %s", e.Pos.Line, e.Pos.Column, e.Message)
+}
+
+func ParseYay(contents string) (YayFile, error) {
+	file := YayFile{}
+	contents = strings.Replace(contents, "\r\n", "\n", -1) // Remove Windows carriage return
+	for i, line := range(strings.Split(contents, "\n")) {
+		tokens, err := tokenizeLine(line, i+1)
+		if err != nil {
+			return file, err
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		statement, err := parseStatement(tokens)
+		if err != nil {
+			return file, err
+		}
+		file.Statements = append(file.Statements, statement)
+	}
+	return file, nil
+}
+
+func tokenizeLine(line string, lineNumber int) ([]Token, error) {
+	tokens := []Token{}
+	runes := []rune(line)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := Position{lineNumber, i+1}
+		switch {
+			case r == '#':
+				return tokens, nil
+			case unicode.IsSpace(r):
+				i++
+			case r == '=':
+				tokens = append(tokens, Token{EqualsToken, "=", pos})
+				i++
+			case r == '+':
+				tokens = append(tokens, Token{PlusToken, "+", pos})
+				i++
+			case r == ',':
+				tokens = append(tokens, Token{CommaToken, ",", pos})
+				i++
+			case r == '"':
+				var text strings.Builder
+				i++
+				for ; i < len(runes) && runes[i] != '"'; i++ {
+					if runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+						i++
+					}
+					text.WriteRune(runes[i])
+				}
+				if i == len(runes) {
+					return nil, &YayError{pos, "the string never ends."}
+				}
+				i++ // Closing quote
+				tokens = append(tokens, Token{StringToken, text.String(), pos})
+			default:
+				start := i
+				for ; i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("#=+,\"", runes[i]); i++ {
+				}
+				tokens = append(tokens, Token{WordToken, string(runes[start:i]), pos})
+		}
+	}
+	return tokens, nil
+}
+
+func parseStatement(tokens []Token) (Statement, error) {
+	statement := Statement{}
+	equals := -1
+	for i, token := range(tokens) {
+		if token.Kind != EqualsToken {
+			continue
+		}
+		if equals >= 0 {
+			return statement, &YayError{token.Pos, "expected exactly one equals sign."}
+		}
+		equals = i
+	}
+	if equals < 0 {
+		return statement, &YayError{tokens[0].Pos, "expected exactly one equals sign."}
+	}
+	statement.Lhs = tokens[:equals]
+	statement.Equals = tokens[equals]
+	statement.Rhs = tokens[equals+1:]
+	if len(statement.Lhs) == 0 {
+		return statement, &YayError{statement.Equals.Pos, "empty left hand side."}
+	}
+	if len(statement.Rhs) == 0 {
+		return statement, &YayError{statement.Equals.Pos, "empty right hand side."}
+	}
+	return statement, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		want  []Token
+	}{
+		{"statement", "A = SPACE", []Token{
+			{WordToken, "A", Position{1, 1}},
+			{EqualsToken, "=", Position{1, 3}},
+			{WordToken, "SPACE", Position{1, 5}},
+		}},
+		{"symbols need no spaces", "LB+RSTICK=A,B", []Token{
+			{WordToken, "LB", Position{1, 1}},
+			{PlusToken, "+", Position{1, 3}},
+			{WordToken, "RSTICK", Position{1, 4}},
+			{EqualsToken, "=", Position{1, 10}},
+			{WordToken, "A", Position{1, 11}},
+			{CommaToken, ",", Position{1, 12}},
+			{WordToken, "B", Position{1, 13}},
+		}},
+		{"string", `A = "space key"`, []Token{
+			{WordToken, "A", Position{1, 1}},
+			{EqualsToken, "=", Position{1, 3}},
+			{StringToken, "space key", Position{1, 5}},
+		}},
+		{"escapes", `"say \"hi\" \\ \n"`, []Token{
+			{StringToken, `say "hi" \ \n`, Position{1, 1}},
+		}},
+		{"comment", "A = B # C = D", []Token{
+			{WordToken, "A", Position{1, 1}},
+			{EqualsToken, "=", Position{1, 3}},
+			{WordToken, "B", Position{1, 5}},
+		}},
+		{"hash in a string", `"#1" # comment`, []Token{
+			{StringToken, "#1", Position{1, 1}},
+		}},
+		{"only a comment", "  # A = B", []Token{}},
+		{"blank", " \t ", []Token{}},
+		{"columns count characters", "\"é€\" = ü", []Token{
+			{StringToken, "é€", Position{1, 1}},
+			{EqualsToken, "=", Position{1, 6}},
+			{WordToken, "ü", Position{1, 8}},
+		}},
+		{"tab is one column", "\tA", []Token{
+			{WordToken, "A", Position{1, 2}},
+		}},
+	}
+	for _, test := range(tests) {
+		got, err := tokenizeLine(test.line, 1)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: tokenizeLine(%q) = %v, want %v", test.name, test.line, got, test.want)
+		}
+	}
+}
+
+func TestTokenNormalized(t *testing.T) {
+	tests := []struct {
+		token       Token
+		normalized  string
+		word        string
+	}{
+		{Token{WordToken, "dpad_Up", Position{}}, "DPADUP", "DPADUP"},
+		{Token{StringToken, "dpad_Up", Position{}}, "dpad_Up", ""},
+		{Token{EqualsToken, "=", Position{}}, "=", ""},
+	}
+	for _, test := range(tests) {
+		if got := test.token.Normalized(); got != test.normalized {
+			t.Errorf("%v: Normalized() = %q, want %q", test.token, got, test.normalized)
+		}
+		if got := test.token.Word(); got != test.word {
+			t.Errorf("%v: Word() = %q, want %q", test.token, got, test.word)
+		}
+	}
+}
+
+func TestParseYay(t *testing.T) {
+	file, err := ParseYay("# Bindings\r\n\r\nA = SPACE\r\nLB + RSTICK = Q, E\r\n")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(file.Statements) != 2 {
+		t.Fatalf("got %d statements, want 2", len(file.Statements))
+	}
+	second := file.Statements[1]
+	if len(second.Lhs) != 3 || len(second.Rhs) != 3 || second.Equals.Pos != (Position{4, 13}) {
+		t.Errorf("second statement = %v, want 3 tokens, = on line 4, column 13 and 3 tokens", second)
+	}
+}
+
+func TestParseYayErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		contents  string
+		want      YayError
+	}{
+		{"unterminated string", `A = "SPACE`, YayError{Position{1, 5}, "the string never ends."}},
+		{"escaped closing quote", `A = "SPACE\"`, YayError{Position{1, 5}, "the string never ends."}},
+		{"unterminated string on a later line", "A = B\n  C = \"D", YayError{Position{2, 7}, "the string never ends."}},
+		{"multiple equals", "A = B = C", YayError{Position{1, 7}, "expected exactly one equals sign."}},
+		{"no equals", "\n  A B", YayError{Position{2, 3}, "expected exactly one equals sign."}},
+		{"empty left hand side", " = A", YayError{Position{1, 2}, "empty left hand side."}},
+		{"empty right hand side", "A = # B", YayError{Position{1, 3}, "empty right hand side."}},
+		{"empty string counts", `A = ""`, YayError{}},
+	}
+	for _, test := range(tests) {
+		_, err := ParseYay(test.contents)
+		if test.want == (YayError{}) {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", test.name, err)
+			}
+			continue
+		}
+		got, ok := err.(*YayError)
+		if !ok || *got != test.want {
+			t.Errorf("%s: ParseYay(%q) error = %v, want %v", test.name, test.contents, err, &test.want)
+		}
+	}
+}